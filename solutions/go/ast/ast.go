@@ -0,0 +1,122 @@
+// Package ast defines the nodes of the abstract syntax tree created by syntax.Parser. Every node carries the
+// span of input it has been parsed from.
+package ast
+
+import (
+	"fmt"
+
+	"github.com/halimath/calc/token"
+)
+
+// Node defines the interface for all nodes of the tree. Only types contained in this package may satisfy the
+// Node interface.
+type Node interface {
+	ast()
+}
+
+// Number is a number literal.
+type Number struct {
+	// Value is the value of the literal as a float64.
+	Value float64
+	// Literal is the literal as read from the input. Evaluations using other number types than float64
+	// convert it rather than Value in order not to lose precision.
+	Literal string
+	// Span is the location of the number literal.
+	Span token.Span
+}
+
+func (Number) ast() {}
+
+// Ident is an identifier referring to a variable.
+type Ident struct {
+	Name string
+	// Span is the location of the identifier.
+	Span token.Span
+}
+
+func (Ident) ast() {}
+
+// Call is a call of the function Name with the arguments Args.
+type Call struct {
+	Name string
+	Args []Node
+	// Span is the location of the function name.
+	Span token.Span
+}
+
+func (Call) ast() {}
+
+// Op is the operation applied by Operator and Unary nodes.
+type Op int
+
+const (
+	Add Op = iota + 1
+	Sub
+	Mul
+	Div
+	Pow
+)
+
+func (o Op) String() string {
+	switch o {
+	case Add:
+		return "+"
+	case Sub:
+		return "-"
+	case Mul:
+		return "*"
+	case Div:
+		return "/"
+	case Pow:
+		return "^"
+	default:
+		return fmt.Sprintf("Op(%d)", int(o))
+	}
+}
+
+// Operator is a binary operator Op applied to the operands L and R.
+type Operator struct {
+	L, R Node
+	Op   Op
+	// Span is the location of the operator.
+	Span token.Span
+}
+
+func (Operator) ast() {}
+
+// Unary is a prefix sign applied to X. Op is either Add or Sub.
+type Unary struct {
+	X  Node
+	Op Op
+	// Span is the location of the sign.
+	Span token.Span
+}
+
+func (Unary) ast() {}
+
+// Invalid takes the place of an operand that could not be parsed. It only occurs in the partial trees created
+// when recovering from syntax errors.
+type Invalid struct {
+	// Span is the location of the offending input.
+	Span token.Span
+}
+
+func (Invalid) ast() {}
+
+// Assign is a statement assigning the value of X to the variable Name.
+type Assign struct {
+	Name string
+	X    Node
+	// Span is the location of the variable name.
+	Span token.Span
+}
+
+func (Assign) ast() {}
+
+// Program is a script: a sequence of statements, each of which is either an Assign or an expression. It is
+// created by syntax.Parser.Program and never occurs inside an expression.
+type Program struct {
+	Stmts []Node
+}
+
+func (Program) ast() {}
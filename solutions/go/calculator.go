@@ -0,0 +1,155 @@
+// Package calc implements an arithmetic calculator for very long mathematical expressions.
+//
+// Eval and its siblings are convenience wrappers scanning and evaluating an expression in one step using the
+// default engine, engine.AST. The individual steps are available as separate packages: package token defines
+// the tokens and positions, package syntax provides the Scanner and the Parser, package ast the tree created
+// by the Parser and package rpn a converter to reverse polish notation. Package engine evaluates them using
+// one of several strategies, which may be selected at run time.
+//
+// The exported API of these packages is covered by the module's semantic version: it changes incompatibly with
+// a new major version only.
+package calc
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/halimath/calc/decimal"
+	"github.com/halimath/calc/engine"
+	"github.com/halimath/calc/syntax"
+)
+
+// defaultEngine is the engine used by the functions of this package.
+var defaultEngine engine.Engine = engine.AST{}
+
+// Eval evaluates the expression read from r and returns the result as well as any error. Errors caused by
+// the input wrap a *ScanError, *SyntaxError or *EvalError, which describe the offending span of input; use
+// errors.As to access them.
+//
+// The default engine keeps a tree of the whole expression in memory. Use engine.RPN to evaluate expressions
+// too large for that in memory proportional to their nesting depth only.
+func Eval(r io.Reader) (float64, error) {
+	return defaultEngine.Eval(syntax.NewScanner(r), nil)
+}
+
+// Env defines functions and constants available to expressions in addition to the builtin functions.
+type Env = engine.Env
+
+// EvalParallel evaluates the expression read from r like Eval using up to workers goroutines, or as many as
+// there are CPUs if workers is less than 1. It reads all of r before splitting the expression into segments
+// at the operators + and - outside of any parenthesis and evaluating these in parallel, which pays off for
+// input of several megabytes. The result is exactly the one Eval calculates. Input any term of which is
+// invalid, such as one missing an operator like 2(3), is evaluated by Eval as a whole, so the errors reported
+// are the same, too.
+func EvalParallel(r io.Reader, workers int) (float64, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+	return engine.EvalParallel(defaultEngine, src, nil, workers)
+}
+
+// NewEnv creates an empty Env.
+func NewEnv() *Env { return engine.NewEnv() }
+
+// EvalEnv evaluates the expression read from r like Eval, calling the functions and using the constants
+// defined by env. Calls of unknown functions or with the wrong number of arguments are reported as *CallError
+// and names that are not defined as *UndefinedError.
+func EvalEnv(r io.Reader, env *Env) (float64, error) {
+	return defaultEngine.Eval(syntax.NewScanner(r), env)
+}
+
+// EvalBig evaluates the expression read from r using arbitrary precision floating point numbers with a
+// mantissa of prec bits. Number literals are converted with the same precision, so the result does not
+// suffer from float64's limit of about 16 significant decimal digits. Powers with a fractional exponent
+// are not supported and reported as errors.ErrUnsupported. A prec of 0 selects 64 bits, just like
+// big.ParseFloat does.
+func EvalBig(r io.Reader, prec uint) (*big.Float, error) {
+	return defaultEngine.EvalBig(syntax.NewScanner(r), prec)
+}
+
+// EvalRat evaluates the expression read from r using exact rational numbers, so decimal literals such as
+// 0.1 are represented without any rounding. Powers with a fractional exponent are not supported and
+// reported as errors.ErrUnsupported. Use FormatFraction, FormatRepeating or FormatRounded to render the
+// result.
+func EvalRat(r io.Reader) (*big.Rat, error) {
+	return defaultEngine.EvalRat(syntax.NewScanner(r))
+}
+
+// EvalDecimal evaluates the expression read from r using decimal fixed-point numbers. Every number literal as
+// well as every intermediate result is rounded to the scale and using the rounding mode defined by ctx, so
+// the result is what a calculation by hand (or a bookkeeping system) with that many fractional digits
+// produces. Quotients that do not terminate are rounded to ctx.Scale fractional digits. Powers with a
+// fractional exponent are not supported and reported as errors.ErrUnsupported.
+func EvalDecimal(r io.Reader, ctx decimal.Context) (decimal.Decimal, error) {
+	return defaultEngine.EvalDecimal(syntax.NewScanner(r), ctx)
+}
+
+// EvalStrict evaluates the expression read from r like Eval but rejects input that does not strictly conform
+// to the grammar, such as numbers with leading zeros (007) or a trailing decimal point (5.), non-ASCII digits
+// and whitespace other than space, \n, \t, \r, \f and \b. Such input is reported as a ScanError of kind
+// LeadingZero, LeadingDot, TrailingDot, RepeatedDot, NonASCIIDigit or InvalidWhitespace.
+func EvalStrict(r io.Reader) (float64, error) {
+	s := syntax.NewScanner(r)
+	s.Strict()
+	return defaultEngine.Eval(s, nil)
+}
+
+// Compile compiles the expression read from r, which may refer to variables, into a Program that evaluates it
+// using float64 numbers any number of times. Evaluate it using Program.Eval passing the values of the
+// variables by name or bind the variables to positions in a slice of values using Program.Bind first, which
+// evaluates faster. Variables without a value are reported as *UndefinedError.
+func Compile(r io.Reader) (*Program, error) {
+	return defaultEngine.Compile(syntax.NewScanner(r), nil)
+}
+
+// CompileEnv compiles the expression read from r like Compile, calling the functions and using the constants
+// defined by env. Names defined as constants are replaced by their value when compiling, so they are not
+// variables of the Program. Changes made to env after compiling do not affect the Program.
+func CompileEnv(r io.Reader, env *Env) (*Program, error) {
+	return defaultEngine.Compile(syntax.NewScanner(r), env)
+}
+
+// EvalScript runs the script read from r and returns the value of its last statement. A script is a sequence of
+// statements separated by semicolons or line breaks, each of which is either an expression or an assignment
+// of the form name = expr, such as
+//
+//	net = 1200 * 12
+//	tax = net * 0.19
+//	net - tax
+//
+// Statements may refer to the variables assigned by preceding ones as well as to the functions and constants
+// defined by env, which may be nil. A line break following an operator or inside parenthesis continues the
+// statement. A script without any statement is reported as ErrInvalidInput.
+func EvalScript(r io.Reader, env *Env) (float64, error) {
+	values, err := RunScript(r, env)
+	if err != nil {
+		return 0, err
+	}
+	if len(values) == 0 {
+		return 0, fmt.Errorf("%w: script contains no statement", ErrInvalidInput)
+	}
+	return values[len(values)-1], nil
+}
+
+// RunScript runs the script read from r like EvalScript but returns the value of every statement in order. The
+// value of an assignment is the value assigned.
+func RunScript(r io.Reader, env *Env) ([]float64, error) {
+	return defaultEngine.Run(syntax.NewScanner(r), env)
+}
+
+// Check parses the expression read from r without evaluating it. Unlike the Eval functions, which stop at the
+// first error, Check skips offending input up to the next operator or parenthesis and continues. It returns
+// an ErrorList containing all errors found, but at most max of them unless max is less than 1.
+func Check(r io.Reader, max int) error {
+	return defaultEngine.Check(syntax.NewScanner(r), max)
+}
+
+// CheckStrict works like Check but rejects input that does not strictly conform to the grammar, just like
+// EvalStrict.
+func CheckStrict(r io.Reader, max int) error {
+	s := syntax.NewScanner(r)
+	s.Strict()
+	return defaultEngine.Check(s, max)
+}
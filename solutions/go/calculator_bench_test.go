@@ -0,0 +1,207 @@
+package calc
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/halimath/calc/engine"
+	"github.com/halimath/calc/rpn"
+	"github.com/halimath/calc/syntax"
+	"github.com/halimath/calc/token"
+)
+
+func BenchmarkSuperSimple(b *testing.B) {
+	benchmarkEngines(b, []byte("1 + 2 + 3"))
+}
+
+func BenchmarkSimple(b *testing.B) {
+	benchmarkEngines(b, []byte("38034 - 172.432 * 16864 / 45030 - 162 / (663.45532 * 535)"))
+}
+
+// BenchmarkProgram compares evaluating a compiled program using a map of values to evaluating it using a
+// Binding.
+func BenchmarkProgram(b *testing.B) {
+	const in = "price * (1 + rate) ^ years - fee"
+	vars := map[string]float64{"price": 1000, "rate": 0.05, "years": 10, "fee": 25}
+
+	for _, e := range engine.All() {
+		p, err := e.Compile(syntax.NewScanner(strings.NewReader(in)), nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(e.Name()+"/map", func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				if _, err := p.Eval(vars); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		binding, err := p.Bind("price", "rate", "years", "fee")
+		if err != nil {
+			b.Fatal(err)
+		}
+		values := []float64{1000, 0.05, 10, 25}
+
+		b.Run(e.Name()+"/binding", func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				if _, err := binding.Eval(values); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkRepeated compares repeatedly evaluating a parsed tree or a list of tokens in RPN to running the
+// bytecode compiled from them.
+func BenchmarkRepeated(b *testing.B) {
+	content, err := os.ReadFile(filepath.Join("../../testdata", "1k"))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	root, err := syntax.NewParser(syntax.NewScanner(bytes.NewReader(content))).Expr()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var toks []token.Token
+	c := rpn.New(syntax.NewScanner(bytes.NewReader(content)))
+	for {
+		tok, err := c.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			b.Fatal(err)
+		}
+		toks = append(toks, tok)
+	}
+
+	b.Run("ast/tree", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			if _, err := engine.EvalTree(root); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("ast/bytecode", func(b *testing.B) {
+		benchmarkProgram(b, func() (*engine.Program, error) { return engine.CompileTree(root, nil) })
+	})
+
+	b.Run("rpn/tokens", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			if _, err := engine.EvalRPN(&tokenSlice{toks: toks}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("rpn/bytecode", func(b *testing.B) {
+		benchmarkProgram(b, func() (*engine.Program, error) { return engine.CompileRPN(&tokenSlice{toks: toks}, nil) })
+	})
+}
+
+func benchmarkProgram(b *testing.B, compile func() (*engine.Program, error)) {
+	p, err := compile()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		if _, err := p.Eval(nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// tokenSlice implements token.Reader for a slice of tokens.
+type tokenSlice struct {
+	toks []token.Token
+	next int
+}
+
+func (s *tokenSlice) Next() (token.Token, error) {
+	if s.next == len(s.toks) {
+		return token.Token{}, io.EOF
+	}
+	s.next++
+	return s.toks[s.next-1], nil
+}
+
+func Benchmark1k(b *testing.B) {
+	benchmarkFile("1k", b)
+}
+
+func Benchmark10k(b *testing.B) {
+	benchmarkFile("10k", b)
+}
+
+func Benchmark1m(b *testing.B) {
+	benchmarkFile("1m", b)
+}
+
+func Benchmark10m(b *testing.B) {
+	benchmarkFile("10m", b)
+}
+
+// BenchmarkParallel10m compares evaluating about 10 MB sequentially using the RPN engine to evaluating it in
+// parallel using EvalParallel, which converts the terms to RPN as well, on as many goroutines as there are
+// CPUs. Run it using -cpu 1,2,4,8 to see the speedup gained from additional CPUs. The input is testdata/1m
+// added up ten times, as testdata contains no larger file.
+func BenchmarkParallel10m(b *testing.B) {
+	content, err := os.ReadFile(filepath.Join("../../testdata", "1m"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	content = bytes.Repeat(append(content, " + "...), 10)
+	content = content[:len(content)-len(" + ")]
+
+	b.Run("sequential", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			if _, err := (engine.RPN{}).Eval(syntax.NewScanner(bytes.NewReader(content)), nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("parallel", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			if _, err := engine.EvalParallel(engine.RPN{}, content, nil, 0); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func benchmarkFile(filename string, b *testing.B) {
+	content, err := os.ReadFile(filepath.Join("../../testdata", filename))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	benchmarkEngines(b, content)
+}
+
+// benchmarkEngines runs a sub-benchmark evaluating content for every engine, so their results can be compared
+// using the same binary.
+func benchmarkEngines(b *testing.B, content []byte) {
+	for _, e := range engine.All() {
+		b.Run(e.Name(), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				_, err := e.Eval(syntax.NewScanner(bytes.NewReader(content)), nil)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
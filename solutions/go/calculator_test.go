@@ -0,0 +1,625 @@
+package calc
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/halimath/calc/decimal"
+	"github.com/halimath/expect"
+	"github.com/halimath/expect/is"
+)
+
+func TestEval(t *testing.T) {
+	type testCase struct {
+		in   string
+		want float64
+		err  error
+	}
+
+	tests := []testCase{
+		{in: "2+3", want: 5},
+		{in: "2/3", want: 2.0 / 3.0},
+		{in: "2+3+4", want: 9},
+		{in: "2+3*4", want: 14},
+		{in: "2+3*4-5", want: 9},
+		{in: "2+3*(4-5)", want: -1},
+		{in: "8 / 2 * (2 + 2)", want: 16},
+
+		{in: "10 - 4 - 3", want: 3},
+		{in: "8 / 4 / 2", want: 1},
+		{in: "10 - 4 + 3", want: 9},
+		{in: "10 + 4 - 3", want: 11},
+		{in: "8 / 4 * 2", want: 4},
+		{in: "8 * 4 / 2", want: 16},
+		{in: "1 - 2 - 3 - 4", want: -8},
+		{in: "64 / 4 / 2 / 2", want: 4},
+		{in: "10 - 2 * 3 - 1", want: 3},
+		{in: "10 - 6 / 3 - 1", want: 7},
+		{in: "2 * 3 - 4 / 2 + 1", want: 5},
+		{in: "20 / 2 / 5 * 3 - 4 - 1", want: 1},
+		{in: "1 + 2 * 3 * 4 / 8 - 5", want: -1},
+		{in: "(10 - 4) - 3", want: 3},
+		{in: "10 - (4 - 3)", want: 9},
+		{in: "8 / (4 / 2)", want: 4},
+
+		{in: "-3", want: -3},
+		{in: "+3", want: 3},
+		{in: "- -4", want: 4},
+		{in: "-+-4", want: 4},
+		{in: "-2 * 3", want: -6},
+		{in: "2 * -3", want: -6},
+		{in: "2 - -3", want: 5},
+		{in: "2 + -3", want: -1},
+		{in: "-(1 + 2)", want: -3},
+		{in: "-(1 + 2) * -(3 - 5)", want: -6},
+		{in: "-8 / -2 / 2", want: 2},
+		{in: "(-1)", want: -1},
+
+		{in: "2 ^ 3", want: 8},
+		{in: "2 ^ 3 ^ 2", want: 512},
+		{in: "(2 ^ 3) ^ 2", want: 64},
+		{in: "2 * 3 ^ 2", want: 18},
+		{in: "3 ^ 2 * 2", want: 18},
+		{in: "1 + 2 ^ 2 - 1", want: 4},
+		{in: "-2 ^ 2", want: -4},
+		{in: "(-2) ^ 2", want: 4},
+		{in: "(-2) ^ 3", want: -8},
+		{in: "2 ^ -1", want: 0.5},
+		{in: "4 ^ 0.5", want: 2},
+		{in: "0 ^ 0", want: 1},
+
+		{in: "", want: 0, err: ErrInvalidInput},
+		{in: "2+", want: 0, err: ErrInvalidInput},
+		{in: "-", want: 0, err: ErrInvalidInput},
+		{in: "2*-", want: 0, err: ErrInvalidInput},
+		{in: "2/0", want: 0, err: ErrDivisionByZero},
+		{in: "0 ^ -1", want: 0, err: ErrDomain},
+		{in: "(-8) ^ 0.5", want: 0, err: ErrDomain},
+		{in: "10 ^ 400", want: 0, err: ErrDomain},
+		{in: "abc", want: 0, err: ErrUndefined},
+		{in: "2.3.", want: 0, err: ErrInvalidInput},
+		{in: "sqrt(2 * 8) - max(1, 2, 3)", want: 1},
+		{in: "sqrt(-4)", want: 0, err: ErrDomain},
+		{in: "sqrt()", want: 0, err: ErrArity},
+		{in: "sqr(4)", want: 0, err: ErrUnknownFunction},
+	}
+
+	for _, test := range tests {
+		got, err := Eval(strings.NewReader(test.in))
+
+		expect.WithMessage(t, "in: %q", test.in).That(
+			is.Error(err, test.err),
+			is.DeepEqualTo(got, test.want),
+		)
+	}
+}
+
+func TestEval_long(t *testing.T) {
+	// Limit the goroutine stacks to make sure neither parsing nor evaluation recurse per token or per
+	// parenthesis. Exceeding the limit aborts the test binary.
+	defer debug.SetMaxStack(debug.SetMaxStack(1 << 20))
+
+	const n = 100_000
+
+	type testCase struct {
+		label string
+		in    string
+		want  float64
+	}
+
+	tests := []testCase{
+		{label: "flat sum", in: "1" + strings.Repeat(" + 1", n-1), want: n},
+		{label: "flat difference", in: "0" + strings.Repeat(" - 1", n), want: -n},
+		{label: "flat mixed", in: "1" + strings.Repeat(" * 2 / 2", n), want: 1},
+		{label: "nested", in: strings.Repeat("(1 + ", n) + "0" + strings.Repeat(")", n), want: n},
+	}
+
+	for _, test := range tests {
+		got, err := Eval(strings.NewReader(test.in))
+
+		expect.WithMessage(t, "%s", test.label).That(
+			is.NoError(err),
+			is.EqualTo(got, test.want),
+		)
+	}
+}
+
+func TestEvalBig(t *testing.T) {
+	type testCase struct {
+		in   string
+		prec uint
+		want string
+		err  error
+	}
+
+	tests := []testCase{
+		{in: "2+3", prec: 53, want: "5"},
+		{in: "0.1 + 0.2", prec: 53, want: "0.30000000000000004"},
+		{in: "0.1 + 0.2", prec: 256, want: "0.3"},
+		{in: "12345678901234567890 * 10 + 1", prec: 128, want: "123456789012345678901"},
+		{in: "10 - 4 - 3", prec: 64, want: "3"},
+		{in: "-2 ^ 2", prec: 64, want: "-4"},
+		{in: "2 ^ 3 ^ 2", prec: 64, want: "512"},
+		{in: "2 ^ -2", prec: 64, want: "0.25"},
+		{in: "2 ^ 100", prec: 128, want: "1267650600228229401496703205376"},
+		{in: "1 / 3", prec: 0, want: "0.33333333333333333334"},
+
+		{in: "abc", err: ErrUndefined},
+		{in: "2/0", err: ErrDivisionByZero},
+		{in: "0 ^ -1", err: ErrDomain},
+		{in: "2 ^ 5000000000", err: ErrDomain},
+		{in: "0.5 ^ -2147483647", prec: 64, err: ErrDomain},
+		{in: "(-0.5) ^ -2147483647", prec: 64, err: ErrDomain},
+		{in: "0.1 ^ -2147483647", prec: 64, err: ErrDomain},
+		{in: "4 ^ 0.5", err: errors.ErrUnsupported},
+	}
+
+	for _, test := range tests {
+		got, err := EvalBig(strings.NewReader(test.in), test.prec)
+
+		var gotText string
+		if got != nil {
+			gotText = got.Text('f', -1)
+		}
+
+		expect.WithMessage(t, "in: %q", test.in).That(
+			is.Error(err, test.err),
+			is.EqualTo(gotText, test.want),
+		)
+	}
+}
+
+func TestEvalBig_testdata(t *testing.T) {
+	type testCase struct {
+		file  string
+		exact string
+	}
+
+	tests := []testCase{
+		{file: "1k", exact: "248253190541.038973952"},
+		{file: "10k", exact: "214512826488689492.561302580234682368"},
+		{file: "100k", exact: "481585283158357790643193.618984281434882048"},
+		{file: "1m", exact: "830415166156152541415341745.310369514614543011467040391168"},
+	}
+
+	for _, test := range tests {
+		content, err := os.ReadFile(filepath.Join("../../testdata", test.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// With a 53 bit mantissa, big.Float rounds exactly like float64 does.
+		want, err := Eval(bytes.NewReader(content))
+		expect.That(t, is.NoError(err))
+
+		got, err := EvalBig(bytes.NewReader(content), 53)
+		expect.WithMessage(t, "file: %s", test.file).That(is.NoError(err))
+
+		f, _ := got.Float64()
+		expect.WithMessage(t, "file: %s", test.file).That(is.EqualTo(f, want))
+
+		if test.exact == "" {
+			continue
+		}
+
+		// Number literals are not exact in binary, but 2048 bits are precise enough to round to all the
+		// decimal places of the exact result.
+		got, err = EvalBig(bytes.NewReader(content), 2048)
+		places := len(test.exact) - strings.IndexByte(test.exact, '.') - 1
+		expect.WithMessage(t, "file: %s", test.file).That(
+			is.NoError(err),
+			is.EqualTo(got.Text('f', places), test.exact),
+		)
+
+		exact, err := EvalRat(bytes.NewReader(content))
+		expect.WithMessage(t, "file: %s", test.file).That(
+			is.NoError(err),
+			is.EqualTo(FormatRepeating(exact, -1), test.exact),
+		)
+	}
+}
+
+func TestEvalParallel(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("../../testdata", "1m"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := Eval(bytes.NewReader(content))
+	expect.That(t, is.NoError(err))
+
+	got, err := EvalParallel(bytes.NewReader(content), 4)
+	expect.That(t,
+		is.NoError(err),
+		is.EqualTo(got, want),
+	)
+
+	_, err = EvalParallel(strings.NewReader("1 + 2 *"), 4)
+	expect.That(t, is.Error(err, ErrInvalidInput))
+
+	// A term missing an operator is reported like Eval does rather than split into valid terms.
+	in := strings.Repeat("1 + ", 200_000) + "1 2 + 1"
+	_, wantErr := Eval(strings.NewReader(in))
+	_, err = EvalParallel(strings.NewReader(in), 4)
+	expect.That(t,
+		is.Error(err, ErrInvalidInput),
+		is.EqualTo(err.Error(), wantErr.Error()),
+	)
+}
+
+func TestEvalRat(t *testing.T) {
+	type testCase struct {
+		in   string
+		want string
+		err  error
+	}
+
+	tests := []testCase{
+		{in: "2+3", want: "5"},
+		{in: "0.1 + 0.2", want: "3/10"},
+		{in: "1 / 3", want: "1/3"},
+		{in: "1 / 3 * 3", want: "1"},
+		{in: "10 - 4 - 3", want: "3"},
+		{in: "8 / 4 / 2", want: "1"},
+		{in: "-(1 + 2) / 4", want: "-3/4"},
+		{in: "2 ^ 3 ^ 2", want: "512"},
+		{in: "(2 / 3) ^ -2", want: "9/4"},
+		{in: "(-2) ^ 3", want: "-8"},
+		{in: "0 ^ 0", want: "1"},
+		{in: "12345678901234567890 * 10 + 1", want: "123456789012345678901"},
+		{in: "(-1) ^ 2147483647", want: "-1"},
+		{in: "(1 / 2) ^ -20", want: "1048576"},
+
+		{in: "abc", err: ErrUndefined},
+		{in: "2/0", err: ErrDivisionByZero},
+		{in: "2/(0.5 - 0.5)", err: ErrDivisionByZero},
+		{in: "0 ^ -1", err: ErrDomain},
+		{in: "2 ^ 2147483647", err: ErrDomain},
+		{in: "(2 / 3) ^ -2000000", err: ErrDomain},
+		{in: "4 ^ 0.5", err: errors.ErrUnsupported},
+	}
+
+	for _, test := range tests {
+		got, err := EvalRat(strings.NewReader(test.in))
+
+		var gotText string
+		if got != nil {
+			gotText = FormatFraction(got)
+		}
+
+		expect.WithMessage(t, "in: %q", test.in).That(
+			is.Error(err, test.err),
+			is.EqualTo(gotText, test.want),
+		)
+	}
+}
+
+func TestEvalDecimal(t *testing.T) {
+	type testCase struct {
+		in   string
+		ctx  decimal.Context
+		want string
+		err  error
+	}
+
+	cents := decimal.Context{Scale: 2, Rounding: decimal.HalfEven}
+
+	tests := []testCase{
+		{in: "0.1 + 0.2", ctx: cents, want: "0.30"},
+		{in: "19.99 * 3", ctx: cents, want: "59.97"},
+		{in: "100 / 3", ctx: cents, want: "33.33"},
+		{in: "100 / 3 * 3", ctx: cents, want: "99.99"},
+		{in: "10 - 4 - 3", ctx: cents, want: "3.00"},
+		{in: "-(1.005 + 1)", ctx: cents, want: "-2.00"},
+		{in: "1.005 + 1", ctx: decimal.Context{Scale: 2, Rounding: decimal.HalfUp}, want: "2.01"},
+		{in: "2 / 3", ctx: decimal.Context{Scale: 2, Rounding: decimal.Down}, want: "0.66"},
+		{in: "2 / 3", ctx: decimal.Context{Scale: 2, Rounding: decimal.Ceiling}, want: "0.67"},
+		{in: "-2 / 3", ctx: decimal.Context{Scale: 2, Rounding: decimal.Floor}, want: "-0.67"},
+		{in: "1.1 ^ 2", ctx: cents, want: "1.21"},
+		{in: "2 ^ -2", ctx: decimal.Context{Scale: 4}, want: "0.2500"},
+		{in: "7 / 2", ctx: decimal.Context{}, want: "4"},
+		{in: ".5 + 1", ctx: cents, want: "1.50"},
+		{in: "5. + 1", ctx: cents, want: "6.00"},
+		{in: "1 ^ 2147483647", ctx: cents, want: "1.00"},
+		{in: "0.5 ^ 20", ctx: decimal.Context{Scale: 20}, want: "0.00000095367431640625"},
+
+		{in: "abc", ctx: cents, err: ErrUndefined},
+		{in: "2/0", ctx: cents, err: ErrDivisionByZero},
+		{in: "2/0.001", ctx: cents, err: ErrDivisionByZero},
+		{in: "0 ^ -1", ctx: cents, err: ErrDomain},
+		{in: "2 ^ 2147483647", ctx: cents, err: ErrDomain},
+		{in: "0.5 ^ -2000000", ctx: cents, err: ErrDomain},
+		{in: "4 ^ 0.5", ctx: cents, err: errors.ErrUnsupported},
+	}
+
+	for _, test := range tests {
+		got, err := EvalDecimal(strings.NewReader(test.in), test.ctx)
+
+		var gotText string
+		if err == nil {
+			gotText = got.String()
+		}
+
+		expect.WithMessage(t, "in: %q", test.in).That(
+			is.Error(err, test.err),
+			is.EqualTo(gotText, test.want),
+		)
+	}
+}
+
+func TestEval_errorPositions(t *testing.T) {
+	type testCase struct {
+		in   string
+		want string
+	}
+
+	tests := []testCase{
+		{in: "1 +\n 2 / 0", want: "2:4: division by zero"},
+		{in: "1 +\n 2 / (2 ^ 0.5 - 2 ^ 0.5)", want: "2:4: division by zero"},
+		{in: "(0 ^ -1)", want: "1:4: domain error: 0 ^ -1"},
+		{in: "1 + #", want: "invalid input: 1:5: scan failed: invalid input rune: #"},
+		{in: "1 +\n  x", want: "2:3: undefined variable: x"},
+	}
+
+	for _, test := range tests {
+		_, err := Eval(strings.NewReader(test.in))
+
+		expect.WithMessage(t, "in: %q", test.in).That(
+			is.EqualTo(err.Error(), test.want),
+		)
+	}
+}
+
+func TestEval_errorTypes(t *testing.T) {
+	t.Run("ScanError", func(t *testing.T) {
+		_, err := Eval(strings.NewReader("1 + #"))
+
+		var got *ScanError
+		expect.That(t,
+			is.EqualTo(errors.As(err, &got), true),
+			is.EqualTo(got.Kind, InvalidRune),
+			is.EqualTo(got.Span.Start, Pos{Offset: 4, Line: 1, Column: 5}),
+			is.EqualTo(got.Text, "#"),
+		)
+	})
+
+	t.Run("malformed number", func(t *testing.T) {
+		_, err := Eval(strings.NewReader("2 * 1.2.3"))
+
+		var got *ScanError
+		expect.That(t,
+			is.Error(err, ErrInvalidInput),
+			is.EqualTo(errors.As(err, &got), true),
+			is.EqualTo(got.Kind, MalformedNumber),
+			is.EqualTo(got.Span.Start, Pos{Offset: 4, Line: 1, Column: 5}),
+			is.EqualTo(got.Text, "1.2.3"),
+		)
+	})
+
+	t.Run("SyntaxError", func(t *testing.T) {
+		_, err := Eval(strings.NewReader("(1 + 2) * (3 +"))
+
+		var got *SyntaxError
+		expect.That(t,
+			is.EqualTo(errors.As(err, &got), true),
+			is.EqualTo(got.Kind, UnexpectedToken),
+			is.EqualTo(got.Span.Start, Pos{Offset: 14, Line: 1, Column: 15}),
+			is.EqualTo(got.Found, ""),
+			is.DeepEqualTo(got.Expected, []string{"number", "identifier", "(", "+", "-"}),
+		)
+	})
+
+	t.Run("unclosed parenthesis", func(t *testing.T) {
+		_, err := Eval(strings.NewReader("2 * (3 + 4"))
+
+		var got *SyntaxError
+		expect.That(t,
+			is.EqualTo(errors.As(err, &got), true),
+			is.EqualTo(got.Kind, UnclosedParen),
+			is.EqualTo(got.Opening.Start, Pos{Offset: 4, Line: 1, Column: 5}),
+		)
+	})
+
+	t.Run("EvalError", func(t *testing.T) {
+		_, err := Eval(strings.NewReader("1 + 6 / (2 - 2)"))
+
+		var got *EvalError
+		expect.That(t,
+			is.Error(err, ErrDivisionByZero),
+			is.EqualTo(errors.As(err, &got), true),
+			is.EqualTo(got.Kind, DivisionByZero),
+			is.EqualTo(got.Span.Start, Pos{Offset: 6, Line: 1, Column: 7}),
+			is.EqualTo(got.Op, "/"),
+			is.DeepEqualTo(got.Operands, []any{6.0, 0.0}),
+		)
+	})
+
+	t.Run("EvalError with rational operands", func(t *testing.T) {
+		_, err := EvalRat(strings.NewReader("4 ^ 0.5"))
+
+		var got *EvalError
+		expect.That(t,
+			is.Error(err, errors.ErrUnsupported),
+			is.EqualTo(errors.As(err, &got), true),
+			is.EqualTo(got.Kind, Unsupported),
+			is.EqualTo(got.Op, "^"),
+			is.EqualTo(got.Operands[1].(*big.Rat).RatString(), "1/2"),
+		)
+	})
+}
+
+func TestCompile(t *testing.T) {
+	p, err := Compile(strings.NewReader("price * (1 + rate)"))
+	expect.That(t,
+		is.NoError(err),
+		is.DeepEqualTo(p.Vars(), []string{"price", "rate"}),
+	)
+
+	got, err := p.Eval(map[string]float64{"price": 100, "rate": 0.25})
+	expect.That(t,
+		is.NoError(err),
+		is.EqualTo(got, 125.0),
+	)
+
+	b, err := p.Bind("rate", "price")
+	expect.That(t, is.NoError(err))
+
+	got, err = b.Eval([]float64{0.5, 10})
+	expect.That(t,
+		is.NoError(err),
+		is.EqualTo(got, 15.0),
+	)
+
+	_, err = Compile(strings.NewReader("price * (1 + rate"))
+	expect.That(t, is.Error(err, ErrInvalidInput))
+}
+
+func TestEvalEnv(t *testing.T) {
+	env := NewEnv()
+	env.Const("pi", math.Pi)
+	env.Func("deg", 1, func(args []float64) (float64, error) { return args[0] * 180 / math.Pi, nil })
+
+	got, err := EvalEnv(strings.NewReader("deg(pi / 2)"), env)
+	expect.That(t,
+		is.NoError(err),
+		is.EqualTo(got, 90.0),
+	)
+
+	_, err = EvalEnv(strings.NewReader("deg(pi, 2)"), env)
+	var callErr *CallError
+	expect.That(t,
+		is.Error(err, ErrArity),
+		is.EqualTo(errors.As(err, &callErr), true),
+		is.EqualTo(callErr.Span.Start, Pos{Offset: 0, Line: 1, Column: 1}),
+	)
+
+	p, err := CompileEnv(strings.NewReader("deg(x) - pi"), env)
+	expect.That(t,
+		is.NoError(err),
+		is.DeepEqualTo(p.Vars(), []string{"x"}),
+	)
+}
+
+func TestEvalScript(t *testing.T) {
+	got, err := EvalScript(strings.NewReader("net = 1200 * 12; tax = net * 0.19; net - tax"), nil)
+	expect.That(t,
+		is.NoError(err),
+		is.EqualTo(got, 11664.0),
+	)
+
+	env := NewEnv()
+	env.Const("rate", 0.5)
+	values, err := RunScript(strings.NewReader("x = 4 * rate\nsqrt(x) +\n  1\n"), env)
+	expect.That(t,
+		is.NoError(err),
+		is.DeepEqualTo(values, []float64{2, math.Sqrt2 + 1}),
+	)
+
+	_, err = EvalScript(strings.NewReader("\n;\n"), nil)
+	expect.That(t,
+		is.Error(err, ErrInvalidInput),
+	)
+
+	_, err = EvalScript(strings.NewReader("x = 1\ny = x +\n  z"), nil)
+	var undefinedErr *UndefinedError
+	expect.That(t,
+		is.Error(err, ErrUndefined),
+		is.EqualTo(errors.As(err, &undefinedErr), true),
+		is.EqualTo(undefinedErr.Span.Start, Pos{Offset: 16, Line: 3, Column: 3}),
+	)
+}
+
+func TestCheck(t *testing.T) {
+	type testCase struct {
+		in   string
+		max  int
+		want []string
+	}
+
+	tests := []testCase{
+		{in: "1 + 2 * (3 - 4)"},
+		{in: "1 + * 2 )\n* #", want: []string{
+			"1:5: invalid syntax: unexpected \"*\"",
+			"1:9: invalid syntax: unexpected \")\"",
+			"2:3: scan failed: invalid input rune: #",
+		}},
+		{in: "1 + * 2 )\n* #", max: 2, want: []string{
+			"1:5: invalid syntax: unexpected \"*\"",
+			"1:9: invalid syntax: unexpected \")\"",
+		}},
+		{in: "1 + $", max: 1, want: []string{"1:5: scan failed: invalid input rune: $"}},
+		{in: "(1 + $", max: 1, want: []string{"1:6: scan failed: invalid input rune: $"}},
+		{in: "1 * (2 + $", max: 1, want: []string{"1:10: scan failed: invalid input rune: $"}},
+	}
+
+	for _, test := range tests {
+		err := Check(strings.NewReader(test.in), test.max)
+
+		var got []string
+		var errs ErrorList
+		if errors.As(err, &errs) {
+			for _, e := range errs {
+				got = append(got, e.Error())
+			}
+		}
+
+		expect.WithMessage(t, "in: %q", test.in).That(
+			is.DeepEqualTo(got, test.want),
+		)
+	}
+}
+
+func TestEvalStrict(t *testing.T) {
+	type testCase struct {
+		in   string
+		want float64
+		kind ScanErrorKind
+	}
+
+	tests := []testCase{
+		{in: "0.5 * (10 +\b2)", want: 6},
+		{in: "007 + 1", kind: LeadingZero},
+		{in: ".5", kind: LeadingDot},
+		{in: "5. + 1", kind: TrailingDot},
+		{in: "1.2.3", kind: RepeatedDot},
+		{in: "\u0661 + 1", kind: NonASCIIDigit},
+		{in: "1\u2003+ 1", kind: InvalidWhitespace},
+	}
+
+	for _, test := range tests {
+		got, err := EvalStrict(strings.NewReader(test.in))
+
+		var scanErr *ScanError
+		if test.kind != 0 {
+			expect.WithMessage(t, "in: %q", test.in).That(
+				is.Error(err, ErrInvalidInput),
+				is.EqualTo(errors.As(err, &scanErr), true),
+				is.EqualTo(scanErr.Kind, test.kind),
+			)
+			continue
+		}
+
+		expect.WithMessage(t, "in: %q", test.in).That(
+			is.NoError(err),
+			is.EqualTo(got, test.want),
+		)
+	}
+}
+
+func TestCheckStrict(t *testing.T) {
+	err := CheckStrict(strings.NewReader("007 + 5. * 1.2.3"), 0)
+
+	var errs ErrorList
+	expect.That(t,
+		is.EqualTo(errors.As(err, &errs), true),
+		is.SliceOfLen(errs, 3),
+		is.NoError(Check(strings.NewReader("007 + 5. * 1.2"), 0)),
+	)
+}
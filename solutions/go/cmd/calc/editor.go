@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// errInterrupt is returned by lineReader.readLine if the user discards the line using Ctrl-C.
+var errInterrupt = errors.New("interrupt")
+
+// lineReader reads the lines entered by the user of the REPL.
+type lineReader interface {
+	// readLine shows prompt and reads a line without its line break. It returns io.EOF once the user closes
+	// the input and errInterrupt if the user discards the line.
+	readLine(prompt string) (string, error)
+	// addHistory adds line to the history of lines to recall.
+	addHistory(line string)
+}
+
+// editor is a lineReader for terminals supporting basic line editing:
+//
+//   - Left, Right, Ctrl-B and Ctrl-F move the cursor, Home, End, Ctrl-A and Ctrl-E move it to the start and
+//     end of the line
+//   - Backspace and Delete delete a rune, Ctrl-W the word before the cursor, Ctrl-U and Ctrl-K everything
+//     before and after the cursor
+//   - Up, Down, Ctrl-P and Ctrl-N recall lines from the history
+//   - Ctrl-C discards the line and Ctrl-D closes the input if the line is empty
+type editor struct {
+	in  *bufio.Reader
+	out io.Writer
+	// raw switches the terminal to raw mode while reading a line, returning a function restoring it. It is
+	// nil if in is not a terminal.
+	raw func() (func(), error)
+
+	// history contains the lines to recall, oldest first.
+	history []string
+}
+
+func (e *editor) addHistory(line string) {
+	if len(e.history) == 0 || e.history[len(e.history)-1] != line {
+		e.history = append(e.history, line)
+	}
+}
+
+func (e *editor) readLine(prompt string) (string, error) {
+	if e.raw != nil {
+		restore, err := e.raw()
+		if err != nil {
+			return "", err
+		}
+		defer restore()
+	}
+
+	var (
+		line []rune
+		pos  int
+		// hist is the index of the history entry shown, which is len(e.history) for the line being edited.
+		// draft keeps that line while browsing the history.
+		hist  = len(e.history)
+		draft []rune
+	)
+
+	recall := func(i int) {
+		if hist == len(e.history) {
+			draft = line
+		}
+		hist = i
+		if hist == len(e.history) {
+			line = draft
+		} else {
+			line = []rune(e.history[hist])
+		}
+		pos = len(line)
+	}
+
+	for {
+		e.refresh(prompt, line, pos)
+
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		key := string(r)
+		if r == 0x1b {
+			key = e.escape()
+		}
+
+		switch key {
+		case "\r", "\n":
+			fmt.Fprint(e.out, "\r\n")
+			return string(line), nil
+		case ctrl('C'):
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", errInterrupt
+		case ctrl('D'):
+			if len(line) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			if pos < len(line) {
+				line = slices.Delete(line, pos, pos+1)
+			}
+		case "[3~":
+			if pos < len(line) {
+				line = slices.Delete(line, pos, pos+1)
+			}
+		case "\x7f", ctrl('H'):
+			if pos > 0 {
+				line = slices.Delete(line, pos-1, pos)
+				pos--
+			}
+		case ctrl('W'):
+			start := pos
+			for start > 0 && unicode.IsSpace(line[start-1]) {
+				start--
+			}
+			for start > 0 && !unicode.IsSpace(line[start-1]) {
+				start--
+			}
+			line = slices.Delete(line, start, pos)
+			pos = start
+		case ctrl('U'):
+			line = slices.Delete(line, 0, pos)
+			pos = 0
+		case ctrl('K'):
+			line = line[:pos]
+		case "[D", "OD", ctrl('B'):
+			pos = max(pos-1, 0)
+		case "[C", "OC", ctrl('F'):
+			pos = min(pos+1, len(line))
+		case "[H", "OH", "[1~", ctrl('A'):
+			pos = 0
+		case "[F", "OF", "[4~", ctrl('E'):
+			pos = len(line)
+		case "[A", "OA", ctrl('P'):
+			if hist > 0 {
+				recall(hist - 1)
+			}
+		case "[B", "OB", ctrl('N'):
+			if hist < len(e.history) {
+				recall(hist + 1)
+			}
+		default:
+			if unicode.IsPrint(r) {
+				line = slices.Insert(slices.Clip(line), pos, r)
+				pos++
+			}
+		}
+	}
+}
+
+// escape reads the rest of an escape sequence following ESC, such as "[A" for the up key.
+func (e *editor) escape() string {
+	var seq strings.Builder
+
+	b, err := e.in.ReadByte()
+	if err != nil || (b != '[' && b != 'O') {
+		return ""
+	}
+	seq.WriteByte(b)
+
+	// Parameters and intermediate bytes are followed by a final byte in the range @ to ~.
+	for {
+		b, err := e.in.ReadByte()
+		if err != nil {
+			return ""
+		}
+		seq.WriteByte(b)
+		if b >= 0x40 && b <= 0x7e {
+			return seq.String()
+		}
+	}
+}
+
+// refresh redraws the line showing prompt, line and the cursor at pos.
+func (e *editor) refresh(prompt string, line []rune, pos int) {
+	var s strings.Builder
+	s.WriteString("\r")
+	s.WriteString(prompt)
+	s.WriteString(string(line))
+	s.WriteString("\x1b[K")
+	if pos < len(line) {
+		fmt.Fprintf(&s, "\x1b[%dD", len(line)-pos)
+	}
+	io.WriteString(e.out, s.String())
+}
+
+// ctrl returns the key entered by pressing Ctrl and c.
+func ctrl(c byte) string {
+	return string(rune(c & 0x1f))
+}
+
+// plainReader is a lineReader for input other than terminals, which does not support editing.
+type plainReader struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func (p plainReader) readLine(prompt string) (string, error) {
+	io.WriteString(p.out, prompt)
+
+	line, err := p.in.ReadString('\n')
+	if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (plainReader) addHistory(string) {}
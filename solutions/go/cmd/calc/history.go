@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// maxHistory is the maximum number of lines loaded from the history file.
+const maxHistory = 1000
+
+// defaultHistoryFile returns the name of the file the REPL's history is kept in by default, which is
+// .calc_history in the user's home directory. It returns an empty name if there is no home directory.
+func defaultHistoryFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".calc_history")
+}
+
+// loadHistory returns the last maxHistory lines of the history file name. A missing file is an empty history.
+func loadHistory(name string) ([]string, error) {
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		lines = append(lines, s.Text())
+		if len(lines) > 2*maxHistory {
+			lines = append(lines[:0], lines[len(lines)-maxHistory:]...)
+		}
+	}
+	if len(lines) > maxHistory {
+		lines = lines[len(lines)-maxHistory:]
+	}
+	return lines, s.Err()
+}
+
+// appendHistory appends line to the history file name, creating it if necessary.
+func appendHistory(name, line string) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/halimath/calc"
+	"github.com/halimath/calc/decimal"
+	"github.com/halimath/calc/diag"
+	"github.com/halimath/calc/engine"
+	"github.com/halimath/calc/syntax"
+)
+
+var (
+	prec        = flag.Uint("prec", 0, "Evaluate using arbitrary precision numbers with a mantissa of the given number of bits (0 uses float64)")
+	exact       = flag.Bool("exact", false, "Evaluate using exact rational numbers and print the result as a fraction")
+	scale       = flag.Int("scale", -1, "Evaluate using decimal fixed-point numbers with the given number of fractional digits (negative uses float64)")
+	rounding    = flag.String("rounding", "half-even", "Rounding mode used with -scale: half-even, half-up, down, ceiling or floor")
+	color       = flag.String("color", "auto", "Colorize error messages: auto, always or never")
+	maxErrors   = flag.Int("max-errors", 10, "Maximum number of syntax errors to report (0 reports all)")
+	strict      = flag.Bool("strict", false, "Reject input not strictly conforming to the grammar, such as leading zeros or non-ASCII digits")
+	engineName  = flag.String("engine", "ast", "Engine used to evaluate the input: ast or rpn")
+	historyFile = flag.String("history", defaultHistoryFile(), "File the lines entered in interactive mode are kept in (empty disables the history)")
+	script      = flag.Bool("script", false, "Run the input as a script of statements separated by semicolons or line breaks, such as x = 2; x * 3, and print the value of the last statement")
+	parallel    = flag.Int("parallel", 0, "Evaluate terms of the input on the given number of goroutines in parallel (0 evaluates sequentially, negative uses all CPUs)")
+)
+
+// in is the input to evaluate and src provides random access to it in order to render diagnostics.
+var (
+	in  io.Reader
+	src io.ReaderAt
+)
+
+func main() {
+	flag.Parse()
+
+	e, err := engine.Lookup(*engineName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", os.Args[0], err)
+		os.Exit(2)
+	}
+
+	if *script && (*prec > 0 || *exact || *scale >= 0) {
+		fmt.Fprintf(os.Stderr, "%s: -script evaluates using float64 numbers and cannot be combined with -prec, -exact or -scale\n", os.Args[0])
+		os.Exit(2)
+	}
+
+	if *parallel != 0 && (*script || *prec > 0 || *exact || *scale >= 0) {
+		fmt.Fprintf(os.Stderr, "%s: -parallel evaluates expressions using float64 numbers and cannot be combined with -script, -prec, -exact or -scale\n", os.Args[0])
+		os.Exit(2)
+	}
+
+	info, err := os.Stdin.Stat()
+	if err == nil && info.Mode()&os.ModeCharDevice != 0 && !*script {
+		// Run an interactive session if stdin is a terminal.
+		runREPL(e)
+		return
+	}
+
+	in, src, err = openInput(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: failed to spool input: %s\n", os.Args[0], err)
+		os.Exit(1)
+	}
+	if s, ok := src.(*spool); ok {
+		defer s.Close()
+	}
+
+	if *strict {
+		// Check the whole input before evaluating it using any kind of numbers.
+		if err := check(e); err != nil {
+			report(err)
+		}
+		in = io.NewSectionReader(src, 0, math.MaxInt64)
+	}
+
+	if *script {
+		values, err := e.Run(syntax.NewScanner(in), nil)
+		if err != nil {
+			fail(e, err)
+		}
+		if len(values) == 0 {
+			fmt.Fprintf(os.Stderr, "%s: script contains no statement\n", os.Args[0])
+			exit(1)
+		}
+
+		fmt.Printf("%.5f\n", values[len(values)-1])
+		return
+	}
+
+	if *scale >= 0 {
+		mode, err := decimal.ParseRoundingMode(*rounding)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", os.Args[0], err)
+			exit(2)
+		}
+
+		result, err := e.EvalDecimal(syntax.NewScanner(in), decimal.Context{Scale: *scale, Rounding: mode})
+		if err != nil {
+			fail(e, err)
+		}
+
+		fmt.Println(result)
+		return
+	}
+
+	if *exact {
+		result, err := e.EvalRat(syntax.NewScanner(in))
+		if err != nil {
+			fail(e, err)
+		}
+
+		fmt.Println(calc.FormatFraction(result))
+		return
+	}
+
+	if *prec > 0 {
+		result, err := e.EvalBig(syntax.NewScanner(in), *prec)
+		if err != nil {
+			fail(e, err)
+		}
+
+		fmt.Println(result.Text('f', 5))
+		return
+	}
+
+	if *parallel != 0 {
+		data, err := io.ReadAll(in)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: failed to read input: %s\n", os.Args[0], err)
+			exit(1)
+		}
+
+		result, err := engine.EvalParallel(e, data, nil, max(*parallel, 0))
+		if err != nil {
+			fail(e, err)
+		}
+
+		fmt.Printf("%.5f\n", result)
+		return
+	}
+
+	result, err := e.Eval(syntax.NewScanner(in), nil)
+	if err != nil {
+		fail(e, err)
+	}
+
+	fmt.Printf("%.5f\n", result)
+}
+
+func fail(e engine.Engine, err error) {
+	var (
+		scanErr   *calc.ScanError
+		syntaxErr *calc.SyntaxError
+	)
+	if !*strict && (errors.As(err, &scanErr) || errors.As(err, &syntaxErr)) {
+		// Evaluation stops at the first error, so check the whole input in order to report all syntax errors
+		// at once.
+		if list := check(e); list != nil {
+			err = list
+		}
+	}
+
+	report(err)
+}
+
+// check checks the whole input using e, as a script if -script is given.
+func check(e engine.Engine) error {
+	if _, ok := src.(*spool); ok {
+		// Spool the rest of the input.
+		io.Copy(io.Discard, in)
+	}
+
+	s := syntax.NewScanner(io.NewSectionReader(src, 0, math.MaxInt64))
+	if *strict {
+		s.Strict()
+	}
+	if *script {
+		return e.CheckScript(s, *maxErrors)
+	}
+	return e.Check(s, *maxErrors)
+}
+
+// report renders err as diagnostics of the input and exits.
+func report(err error) {
+	renderErrors(os.Stderr, "<stdin>", src, err)
+	exit(1)
+}
+
+// exit removes the spool of the input, if any, and exits using code.
+func exit(code int) {
+	if s, ok := src.(*spool); ok {
+		s.Close()
+	}
+	os.Exit(code)
+}
+
+// renderErrors renders err as diagnostics of the source name to w. If err is a calc.ErrorList, every error
+// contained is rendered.
+func renderErrors(w io.Writer, name string, src io.ReaderAt, err error) {
+	var r diag.Renderer = diag.TextRenderer{}
+	if colorize() {
+		r = diag.ANSIRenderer{}
+	}
+
+	errs := []error{err}
+	var list calc.ErrorList
+	if errors.As(err, &list) {
+		errs = list
+	}
+
+	for i, err := range errs {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if rerr := r.Render(w, name, src, diag.FromError(err)); rerr != nil {
+			fmt.Fprintf(w, "%s: failed to evaluate: %s\n", os.Args[0], err)
+		}
+	}
+}
+
+// colorize reports whether diagnostics should be rendered using ANSI colors.
+func colorize() bool {
+	switch *color {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+
+	info, err := os.Stderr.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
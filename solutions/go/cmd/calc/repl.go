@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/halimath/calc"
+	"github.com/halimath/calc/decimal"
+	"github.com/halimath/calc/engine"
+	"github.com/halimath/calc/syntax"
+	"github.com/halimath/calc/token"
+)
+
+// Modes are the number types the REPL calculates with, selected using :mode.
+const (
+	modeFloat   = "float"
+	modeBig     = "big"
+	modeExact   = "exact"
+	modeDecimal = "decimal"
+)
+
+var modes = []string{modeFloat, modeBig, modeExact, modeDecimal}
+
+// maxPrecision limits the precision set using :precision.
+const maxPrecision = 1000
+
+const replHelp = `Enter an expression to evaluate it. An expression with unbalanced parenthesis continues on the
+next line. ans refers to the result of the previous expression.
+
+Commands:
+  :help             show this help
+  :mode [m]         show or set the number type: float, big, exact or decimal
+  :precision [n]    show or set the number of fractional digits: printed in float and big mode, the scale in
+                    decimal mode and unused in exact mode
+  :quit             end the session, just like Ctrl-D
+
+Use the arrow keys to edit the line and to recall previous lines. Ctrl-C discards the input.
+`
+
+// repl is an interactive session evaluating one expression after another. Errors are reported without ending
+// the session.
+type repl struct {
+	e     engine.Engine
+	lines lineReader
+	// out receives results and errOut diagnostics.
+	out, errOut io.Writer
+	// history is the file lines are appended to or empty.
+	history string
+
+	mode string
+	// precision is the number of fractional digits printed in float and big mode and the scale in decimal mode.
+	precision int
+	rounding  decimal.RoundingMode
+
+	// ans is the previous result as a number literal or fraction, empty before the first result.
+	ans string
+}
+
+// runREPL runs a session reading from the terminal connected to stdin.
+func runREPL(e engine.Engine) {
+	r := repl{
+		e:         e,
+		out:       os.Stdout,
+		errOut:    os.Stderr,
+		history:   *historyFile,
+		mode:      modeFloat,
+		precision: 5,
+	}
+
+	switch {
+	case *scale >= 0:
+		r.mode, r.precision = modeDecimal, *scale
+	case *exact:
+		r.mode = modeExact
+	case *prec > 0:
+		r.mode = modeBig
+	}
+
+	mode, err := decimal.ParseRoundingMode(*rounding)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", os.Args[0], err)
+		os.Exit(2)
+	}
+	r.rounding = mode
+
+	in := bufio.NewReader(os.Stdin)
+	if restore, err := makeRaw(os.Stdin.Fd()); err == nil {
+		restore()
+		ed := &editor{in: in, out: os.Stdout, raw: func() (func(), error) { return makeRaw(os.Stdin.Fd()) }}
+		if r.history != "" {
+			if ed.history, err = loadHistory(r.history); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: failed to load history: %s\n", os.Args[0], err)
+			}
+		}
+		r.lines = ed
+	} else {
+		r.lines = plainReader{in: in, out: os.Stdout}
+	}
+
+	fmt.Fprintln(r.out, "Type :help for help, Ctrl-D to quit.")
+	if err := r.run(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", os.Args[0], err)
+		os.Exit(1)
+	}
+}
+
+// run reads and evaluates input until the user ends the session.
+func (r *repl) run() error {
+	var pending []string
+
+	for {
+		prompt := "> "
+		if len(pending) > 0 {
+			prompt = "... "
+		}
+
+		line, err := r.lines.readLine(prompt)
+		if errors.Is(err, errInterrupt) {
+			pending = pending[:0]
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if strings.TrimSpace(line) != "" {
+			r.remember(line)
+		}
+
+		if cmd, ok := strings.CutPrefix(strings.TrimSpace(line), ":"); ok && len(pending) == 0 {
+			if !r.command(cmd) {
+				return nil
+			}
+			continue
+		}
+
+		pending = append(pending, line)
+		input := strings.Join(pending, "\n")
+		if strings.Count(input, "(") > strings.Count(input, ")") {
+			// Continue reading the expression on the next line.
+			continue
+		}
+
+		pending = pending[:0]
+		if strings.TrimSpace(input) != "" {
+			r.eval(input)
+		}
+	}
+}
+
+// remember adds line to the history, persisting it unless there is no history file.
+func (r *repl) remember(line string) {
+	r.lines.addHistory(line)
+	if r.history == "" {
+		return
+	}
+	if err := appendHistory(r.history, line); err != nil {
+		fmt.Fprintf(r.errOut, "%s: failed to save history: %s\n", os.Args[0], err)
+		// Do not report the same error for every line.
+		r.history = ""
+	}
+}
+
+// command executes the command cmd, i.e. "mode exact", and returns whether the session continues.
+func (r *repl) command(cmd string) bool {
+	name, arg, _ := strings.Cut(strings.TrimSpace(cmd), " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case "help":
+		io.WriteString(r.out, replHelp)
+
+	case "mode":
+		if arg == "" {
+			fmt.Fprintln(r.out, r.mode)
+			break
+		}
+		if !slices.Contains(modes, arg) {
+			fmt.Fprintf(r.errOut, "unknown mode %q: use one of %s\n", arg, strings.Join(modes, ", "))
+			break
+		}
+		r.mode = arg
+
+	case "precision":
+		if arg == "" {
+			fmt.Fprintln(r.out, r.precision)
+			break
+		}
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 || n > maxPrecision {
+			fmt.Fprintf(r.errOut, "invalid precision %q: use a number of digits from 0 to %d\n", arg, maxPrecision)
+			break
+		}
+		r.precision = n
+
+	case "quit", "q":
+		return false
+
+	default:
+		fmt.Fprintf(r.errOut, "unknown command :%s, type :help for a list of commands\n", name)
+	}
+
+	return true
+}
+
+// eval evaluates input using the current mode, prints the result and keeps it as ans.
+func (r *repl) eval(input string) {
+	src := r.expand(input)
+	s := r.scanner(src)
+
+	var (
+		result, ans string
+		err         error
+	)
+
+	switch r.mode {
+	case modeBig:
+		var v *big.Float
+		if v, err = r.e.EvalBig(s, r.bits()); err == nil {
+			result, ans = v.Text('f', r.precision), v.Text('f', -1)
+		}
+	case modeExact:
+		var v *big.Rat
+		if v, err = r.e.EvalRat(s); err == nil {
+			result, ans = calc.FormatFraction(v), v.RatString()
+		}
+	case modeDecimal:
+		var v decimal.Decimal
+		if v, err = r.e.EvalDecimal(s, decimal.Context{Scale: r.precision, Rounding: r.rounding}); err == nil {
+			result, ans = v.String(), v.String()
+		}
+	default:
+		var v float64
+		if v, err = r.e.Eval(s, nil); err == nil {
+			result, ans = strconv.FormatFloat(v, 'f', r.precision, 64), strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+
+	if err != nil {
+		var (
+			scanErr   *calc.ScanError
+			syntaxErr *calc.SyntaxError
+		)
+		if errors.As(err, &scanErr) || errors.As(err, &syntaxErr) {
+			// Report all syntax errors at once, just like for input read from a file.
+			if list := r.e.Check(r.scanner(src), *maxErrors); list != nil {
+				err = list
+			}
+		}
+		renderErrors(r.errOut, "<input>", strings.NewReader(src), err)
+		return
+	}
+
+	fmt.Fprintln(r.out, result)
+	r.ans = ans
+}
+
+// expand replaces every reference to ans contained in input by the previous result in parenthesis. The
+// result is kept as text in order to use it in any mode without losing precision.
+func (r *repl) expand(input string) string {
+	if r.ans == "" {
+		return input
+	}
+
+	var b strings.Builder
+	last := 0
+
+	s := syntax.NewScanner(strings.NewReader(input))
+	for {
+		tok, err := s.Next()
+		if err != nil {
+			// Leave the rest of invalid input for the engine to report.
+			break
+		}
+		if tok.Type == token.Ident && tok.Literal == "ans" {
+			b.WriteString(input[last:tok.Offset])
+			b.WriteString("(" + r.ans + ")")
+			last = tok.End()
+		}
+	}
+	b.WriteString(input[last:])
+
+	return b.String()
+}
+
+// scanner creates a Scanner reading src, which is in strict mode if -strict is given.
+func (r *repl) scanner(src string) *syntax.Scanner {
+	s := syntax.NewScanner(strings.NewReader(src))
+	if *strict {
+		s.Strict()
+	}
+	return s
+}
+
+// bits returns the mantissa size used in big mode: the size given using -prec or one sufficient for the
+// number of fractional digits printed.
+func (r *repl) bits() uint {
+	if *prec > 0 {
+		return *prec
+	}
+	return uint(math.Ceil(float64(r.precision)*math.Log2(10))) + 64
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/halimath/calc/engine"
+	"github.com/halimath/expect"
+	"github.com/halimath/expect/is"
+)
+
+func TestREPL(t *testing.T) {
+	var out, errOut bytes.Buffer
+	r := repl{
+		e: engine.AST{},
+		lines: &lines{lines: []string{
+			"1 + 2",
+			"ans * 2",
+			"(1 +",
+			"",
+			"  2) * ans",
+			"1 / 0",
+			":precision 2",
+			"10 / 4",
+			":mode exact",
+			"1 / 3",
+			"ans * 3",
+			":mode decimal",
+			"1 / 3",
+			":mode nope",
+			":nope",
+			"2 +",
+			":quit",
+			"1",
+		}},
+		out:       &out,
+		errOut:    &errOut,
+		mode:      modeFloat,
+		precision: 5,
+	}
+
+	err := r.run()
+
+	expect.That(t,
+		is.NoError(err),
+		is.EqualTo(out.String(), "3.00000\n6.00000\n18.00000\n2.50\n1/3\n1\n0.33\n"),
+		is.EqualTo(strings.Contains(errOut.String(), "division by zero"), true),
+		is.EqualTo(strings.Contains(errOut.String(), "unknown mode \"nope\""), true),
+		is.EqualTo(strings.Contains(errOut.String(), "unknown command :nope"), true),
+		is.EqualTo(strings.Contains(errOut.String(), "unexpected end of input"), true),
+	)
+}
+
+func TestREPL_interrupt(t *testing.T) {
+	var out bytes.Buffer
+	r := repl{
+		e:         engine.AST{},
+		lines:     &lines{lines: []string{"(1 +", "\x03", "2"}},
+		out:       &out,
+		errOut:    io.Discard,
+		mode:      modeFloat,
+		precision: 1,
+	}
+
+	err := r.run()
+
+	expect.That(t,
+		is.NoError(err),
+		is.EqualTo(out.String(), "2.0\n"),
+	)
+}
+
+// lines is a lineReader returning a fixed sequence of lines. The line "\x03" is returned as errInterrupt.
+type lines struct {
+	lines   []string
+	history []string
+}
+
+func (l *lines) readLine(string) (string, error) {
+	if len(l.lines) == 0 {
+		return "", io.EOF
+	}
+	line := l.lines[0]
+	l.lines = l.lines[1:]
+	if line == "\x03" {
+		return "", errInterrupt
+	}
+	return line, nil
+}
+
+func (l *lines) addHistory(line string) { l.history = append(l.history, line) }
+
+func TestEditor(t *testing.T) {
+	type testCase struct {
+		in   string
+		want []string
+		err  error
+	}
+
+	tests := []testCase{
+		{in: "1 + 2\r", want: []string{"1 + 2"}},
+		{in: "12\x1b[D3\x1b[C4\r", want: []string{"1324"}},
+		{in: "abc\x7f\x7fd\r", want: []string{"ad"}},
+		{in: "2 * 3\x01\x1b[3~4\r", want: []string{"4 * 3"}},
+		{in: "1 + 2 + 3\x17\x17x\r", want: []string{"1 + 2 x"}},
+		{in: "1 + 2\x02\x02\x0b\x05)\r", want: []string{"1 +)"}},
+		{in: "1 + 2\x02\x02\x15(\r", want: []string{"( 2"}},
+		{in: "first\rsecond\r\x1b[A\x1b[A\r", want: []string{"first", "second", "first"}},
+		{in: "first\rdraft\x1b[A\x1b[B!\r", want: []string{"first", "draft!"}},
+		{in: "abc\x03", err: errInterrupt},
+		{in: "\x04", err: io.EOF},
+		{in: "ab\x01\x04\r", want: []string{"b"}},
+	}
+
+	for _, test := range tests {
+		e := editor{in: bufio.NewReader(strings.NewReader(test.in)), out: io.Discard}
+
+		var got []string
+		var err error
+		for {
+			var line string
+			line, err = e.readLine("> ")
+			if err != nil {
+				break
+			}
+			got = append(got, line)
+			e.addHistory(line)
+		}
+		if test.err == nil && errors.Is(err, io.EOF) {
+			err = nil
+		}
+
+		expect.WithMessage(t, "in: %q", test.in).That(
+			is.Error(err, test.err),
+			is.DeepEqualTo(got, test.want),
+		)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"io"
+	"os"
+)
+
+// spool keeps a copy of the input read from a pipe or terminal in a temporary file in order to show excerpts
+// of it and to check it as a whole, which takes constant memory whatever the length of the input.
+type spool struct {
+	f *os.File
+}
+
+// newSpool creates a spool backed by a new temporary file. The file is removed right away where the system
+// allows removing an open file and by Close otherwise.
+func newSpool() (*spool, error) {
+	f, err := os.CreateTemp("", "calc-")
+	if err != nil {
+		return nil, err
+	}
+	os.Remove(f.Name())
+	return &spool{f: f}, nil
+}
+
+func (s *spool) Write(p []byte) (int, error) { return s.f.Write(p) }
+
+func (s *spool) ReadAt(p []byte, off int64) (int, error) { return s.f.ReadAt(p, off) }
+
+// Close closes and removes the temporary file.
+func (s *spool) Close() error {
+	err := s.f.Close()
+	os.Remove(s.f.Name())
+	return err
+}
+
+// openInput returns the input to read from f along with random access to it. A regular file provides random
+// access itself, while any other input is copied to a spool as it is read.
+func openInput(f *os.File) (io.Reader, io.ReaderAt, error) {
+	if info, err := f.Stat(); err == nil && info.Mode().IsRegular() {
+		return f, f, nil
+	}
+
+	s, err := newSpool()
+	if err != nil {
+		return nil, nil, err
+	}
+	return io.TeeReader(f, s), s, nil
+}
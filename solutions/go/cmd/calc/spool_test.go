@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/halimath/calc/engine"
+	"github.com/halimath/calc/syntax"
+	"github.com/halimath/expect"
+	"github.com/halimath/expect/is"
+)
+
+func TestOpenInput_pipeTakesConstantMemory(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	// Write 1 + 1 + ... + 1 taking 32 MB to the pipe.
+	const terms = 8 << 20
+	go func() {
+		chunk := bytes.Repeat([]byte("1 + "), 1024)
+		for i := 0; i < terms/1024; i++ {
+			w.Write(chunk)
+		}
+		w.Write([]byte("1"))
+		w.Close()
+	}()
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	in, src, err := openInput(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.(*spool).Close()
+
+	got, err := engine.RPN{}.Eval(syntax.NewScanner(in), nil)
+
+	runtime.ReadMemStats(&after)
+
+	// The end of input is available to show excerpts of it.
+	end := make([]byte, 5)
+	_, rerr := src.ReadAt(end, 4*terms-4)
+
+	expect.That(t,
+		is.NoError(err),
+		is.EqualTo(got, terms+1),
+		is.NoError(rerr),
+		is.EqualTo(string(end), "1 + 1"),
+		is.EqualTo(after.TotalAlloc-before.TotalAlloc < 1<<20, true),
+	)
+}
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package main
+
+import "errors"
+
+// makeRaw is not supported on this platform, so the REPL reads lines without editing them.
+func makeRaw(fd uintptr) (func(), error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,169 @@
+package decimal
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// RoundingMode defines how a Context rounds results that have more fractional digits than its scale.
+type RoundingMode int
+
+const (
+	// HalfEven rounds to the nearest neighbour and ties to the even neighbour ("banker's rounding").
+	HalfEven RoundingMode = iota
+	// HalfUp rounds to the nearest neighbour and ties away from zero ("commercial rounding").
+	HalfUp
+	// Down rounds towards zero, i.e. it truncates.
+	Down
+	// Ceiling rounds towards positive infinity.
+	Ceiling
+	// Floor rounds towards negative infinity.
+	Floor
+)
+
+func (m RoundingMode) String() string {
+	switch m {
+	case HalfEven:
+		return "half-even"
+	case HalfUp:
+		return "half-up"
+	case Down:
+		return "down"
+	case Ceiling:
+		return "ceiling"
+	case Floor:
+		return "floor"
+	default:
+		return fmt.Sprintf("RoundingMode(%d)", int(m))
+	}
+}
+
+// ParseRoundingMode returns the RoundingMode whose String representation is s.
+func ParseRoundingMode(s string) (RoundingMode, error) {
+	for m := HalfEven; m <= Floor; m++ {
+		if m.String() == s {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown rounding mode: %q", s)
+}
+
+// Context performs fixed-point arithmetic on Decimal values. Every result produced by a Context has exactly
+// Scale fractional digits. Results with more digits are rounded using Rounding. The zero value rounds to
+// integral numbers using HalfEven.
+//
+// Addition and subtraction of values with at most Scale fractional digits are always exact. Products and
+// quotients are rounded once, from the exact result. In particular, a quotient that does not terminate, such
+// as 1 / 3, is calculated to Scale fractional digits and rounded with respect to the remainder, so the result
+// is the same as rounding the infinitely precise quotient.
+type Context struct {
+	Scale    int
+	Rounding RoundingMode
+}
+
+// Round returns d with exactly c.Scale fractional digits, rounding d if it has more.
+func (c Context) Round(d Decimal) Decimal {
+	if d.exp == -c.Scale {
+		return d
+	}
+
+	if d.exp > -c.Scale {
+		return Decimal{coef: new(big.Int).Mul(d.c(), pow10(d.exp+c.Scale)), exp: -c.Scale}
+	}
+
+	return Decimal{coef: c.quo(d.c(), pow10(-c.Scale-d.exp)), exp: -c.Scale}
+}
+
+// Add returns a + b rounded to c.Scale.
+func (c Context) Add(a, b Decimal) Decimal {
+	x, y := align(a, b)
+	return c.Round(Decimal{coef: new(big.Int).Add(x, y), exp: min(a.exp, b.exp)})
+}
+
+// Sub returns a - b rounded to c.Scale.
+func (c Context) Sub(a, b Decimal) Decimal {
+	x, y := align(a, b)
+	return c.Round(Decimal{coef: new(big.Int).Sub(x, y), exp: min(a.exp, b.exp)})
+}
+
+// Mul returns a * b rounded to c.Scale.
+func (c Context) Mul(a, b Decimal) Decimal {
+	return c.Round(Decimal{coef: new(big.Int).Mul(a.c(), b.c()), exp: a.exp + b.exp})
+}
+
+// Quo returns a / b rounded to c.Scale. It panics if b is zero.
+func (c Context) Quo(a, b Decimal) Decimal {
+	if b.Sign() == 0 {
+		panic("decimal: division by zero")
+	}
+
+	// a / b = (a.coef / b.coef) * 10^(a.exp - b.exp), so the quotient's coefficient for an exponent of
+	// -c.Scale is a.coef * 10^shift / b.coef.
+	num, den := a.c(), b.c()
+	if shift := a.exp - b.exp + c.Scale; shift >= 0 {
+		num = new(big.Int).Mul(num, pow10(shift))
+	} else {
+		den = new(big.Int).Mul(den, pow10(-shift))
+	}
+
+	return Decimal{coef: c.quo(num, den), exp: -c.Scale}
+}
+
+// Pow returns d ^ n rounded to c.Scale. Negative exponents are calculated as 1 / d ^ -n, so Pow panics if d is
+// zero and n is negative.
+func (c Context) Pow(d Decimal, n int) Decimal {
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+
+	// Trailing zeros of the coefficient would be raised to the power of n, too.
+	d = d.trim()
+
+	p := Decimal{
+		coef: new(big.Int).Exp(d.c(), big.NewInt(int64(abs)), nil),
+		exp:  d.exp * abs,
+	}
+
+	if n < 0 {
+		return c.Quo(New(1, 0), p)
+	}
+	return c.Round(p)
+}
+
+// quo returns num / den rounded to an integer using c.Rounding.
+func (c Context) quo(num, den *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+
+	// The sign of the exact quotient; q has been truncated towards zero.
+	sign := num.Sign() * den.Sign()
+
+	var away bool
+	switch c.Rounding {
+	case Down:
+		away = false
+	case Ceiling:
+		away = sign > 0
+	case Floor:
+		away = sign < 0
+	default:
+		// Compare the remainder with half of the divisor.
+		half := new(big.Int).Abs(r)
+		half.Lsh(half, 1)
+		switch half.CmpAbs(den) {
+		case 1:
+			away = true
+		case 0:
+			away = c.Rounding == HalfUp || q.Bit(0) == 1
+		}
+	}
+
+	if away {
+		q.Add(q, big.NewInt(int64(sign)))
+	}
+
+	return q
+}
@@ -0,0 +1,182 @@
+// Package decimal implements base-10 fixed-point numbers as needed for calculations that must not suffer
+// from binary rounding, such as sums of currency amounts.
+//
+// A Decimal is an arbitrary precision coefficient scaled by a power of ten. Decimals are immutable; all
+// operations return new values. Arithmetic is performed by a Context, which defines the number of fractional
+// digits (the scale) every result is rounded to as well as the rounding mode to apply.
+package decimal
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ErrSyntax is returned by Parse when the input is not a valid decimal number.
+var ErrSyntax = errors.New("invalid decimal syntax")
+
+// Decimal represents the number coef * 10^exp. The zero value represents 0 and is ready for use.
+type Decimal struct {
+	coef *big.Int
+	exp  int
+}
+
+// New creates a Decimal representing coef * 10^exp.
+func New(coef int64, exp int) Decimal {
+	return Decimal{coef: big.NewInt(coef), exp: exp}
+}
+
+// Parse parses s as a decimal number of the form [sign] digits [ "." digits ]. Either digits may be empty,
+// but not both, so .5 and 5. are valid like they are in expressions. The number is represented exactly, i.e.
+// Parse("1.50") yields a Decimal with two fractional digits.
+func Parse(s string) (Decimal, error) {
+	digits := s
+	if len(digits) > 0 && (digits[0] == '-' || digits[0] == '+') {
+		digits = digits[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(digits, ".")
+	if len(intPart) == 0 && len(fracPart) == 0 {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrSyntax, s)
+	}
+
+	for _, part := range []string{intPart, fracPart} {
+		for i := 0; i < len(part); i++ {
+			if part[i] < '0' || part[i] > '9' {
+				return Decimal{}, fmt.Errorf("%w: %q", ErrSyntax, s)
+			}
+		}
+	}
+
+	coef, _ := new(big.Int).SetString(intPart+fracPart, 10)
+	if s[0] == '-' {
+		coef.Neg(coef)
+	}
+
+	return Decimal{coef: coef, exp: -len(fracPart)}, nil
+}
+
+// Coef returns d's coefficient.
+func (d Decimal) Coef() *big.Int { return new(big.Int).Set(d.c()) }
+
+// Exp returns d's exponent.
+func (d Decimal) Exp() int { return d.exp }
+
+// Scale returns the number of fractional digits of d, which is 0 for integral coefficients with a positive
+// exponent.
+func (d Decimal) Scale() int { return max(0, -d.exp) }
+
+// Sign returns -1, 0 or +1 depending on d being negative, zero or positive.
+func (d Decimal) Sign() int { return d.c().Sign() }
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal { return Decimal{coef: new(big.Int).Neg(d.c()), exp: d.exp} }
+
+// Cmp compares d and o and returns -1, 0 or +1 depending on d being less than, equal to or greater than o.
+func (d Decimal) Cmp(o Decimal) int {
+	a, b := align(d, o)
+	return a.Cmp(b)
+}
+
+// IsInt returns whether d is an integral number.
+func (d Decimal) IsInt() bool {
+	if d.exp >= 0 {
+		return true
+	}
+	_, r := new(big.Int).QuoRem(d.c(), pow10(-d.exp), new(big.Int))
+	return r.Sign() == 0
+}
+
+// Int64 returns d as an int64 and whether the conversion has been exact, i.e. whether d is integral and
+// within the range of int64.
+func (d Decimal) Int64() (int64, bool) {
+	if !d.IsInt() {
+		return 0, false
+	}
+
+	var i *big.Int
+	if d.exp >= 0 {
+		i = new(big.Int).Mul(d.c(), pow10(d.exp))
+	} else {
+		i = new(big.Int).Quo(d.c(), pow10(-d.exp))
+	}
+
+	if !i.IsInt64() {
+		return 0, false
+	}
+	return i.Int64(), true
+}
+
+// Rat returns d as an exact rational number.
+func (d Decimal) Rat() *big.Rat {
+	if d.exp >= 0 {
+		return new(big.Rat).SetInt(new(big.Int).Mul(d.c(), pow10(d.exp)))
+	}
+	return new(big.Rat).SetFrac(d.c(), pow10(-d.exp))
+}
+
+// String formats d in plain decimal notation showing exactly d.Scale() fractional digits, such as "-12.50".
+func (d Decimal) String() string {
+	if d.exp >= 0 {
+		return new(big.Int).Mul(d.c(), pow10(d.exp)).String()
+	}
+
+	digits := new(big.Int).Abs(d.c()).String()
+	scale := -d.exp
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+
+	var b strings.Builder
+	if d.Sign() < 0 {
+		b.WriteByte('-')
+	}
+	b.WriteString(digits[:len(digits)-scale])
+	b.WriteByte('.')
+	b.WriteString(digits[len(digits)-scale:])
+
+	return b.String()
+}
+
+func (d Decimal) c() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// align returns the coefficients of a and b scaled to the smaller of both exponents.
+func align(a, b Decimal) (*big.Int, *big.Int) {
+	switch {
+	case a.exp == b.exp:
+		return a.c(), b.c()
+	case a.exp > b.exp:
+		return new(big.Int).Mul(a.c(), pow10(a.exp-b.exp)), b.c()
+	default:
+		return a.c(), new(big.Int).Mul(b.c(), pow10(b.exp-a.exp))
+	}
+}
+
+// trim returns d with the trailing zeros of its coefficient removed, adjusting the exponent accordingly.
+func (d Decimal) trim() Decimal {
+	if d.Sign() == 0 {
+		return Decimal{}
+	}
+
+	coef, exp := new(big.Int).Set(d.c()), d.exp
+	ten := big.NewInt(10)
+	q, r := new(big.Int), new(big.Int)
+	for {
+		q.QuoRem(coef, ten, r)
+		if r.Sign() != 0 {
+			return Decimal{coef: coef, exp: exp}
+		}
+		coef.Set(q)
+		exp++
+	}
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,155 @@
+package decimal
+
+import (
+	"testing"
+
+	"github.com/halimath/expect"
+	"github.com/halimath/expect/is"
+)
+
+func TestParse(t *testing.T) {
+	type testCase struct {
+		in   string
+		want string
+		err  error
+	}
+
+	tests := []testCase{
+		{in: "0", want: "0"},
+		{in: "12", want: "12"},
+		{in: "12.50", want: "12.50"},
+		{in: "-0.05", want: "-0.05"},
+		{in: "+3.1", want: "3.1"},
+		{in: "123456789012345678901234567890.123", want: "123456789012345678901234567890.123"},
+
+		{in: ".5", want: "0.5"},
+		{in: "-.25", want: "-0.25"},
+		{in: "5.", want: "5"},
+
+		{in: "", err: ErrSyntax},
+		{in: "-", err: ErrSyntax},
+		{in: ".", err: ErrSyntax},
+		{in: "-.", err: ErrSyntax},
+		{in: "1.2.3", err: ErrSyntax},
+		{in: "1e3", err: ErrSyntax},
+	}
+
+	for _, test := range tests {
+		got, err := Parse(test.in)
+
+		var gotText string
+		if err == nil {
+			gotText = got.String()
+		}
+
+		expect.WithMessage(t, "in: %q", test.in).That(
+			is.Error(err, test.err),
+			is.EqualTo(gotText, test.want),
+		)
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	var zero Decimal
+
+	expect.That(t,
+		is.EqualTo(zero.String(), "0"),
+		is.EqualTo(zero.Sign(), 0),
+		is.EqualTo(New(5, 2).String(), "500"),
+		is.EqualTo(New(5, -3).String(), "0.005"),
+		is.EqualTo(New(-1250, -2).String(), "-12.50"),
+		is.EqualTo(New(-1250, -2).Scale(), 2),
+		is.EqualTo(New(1250, -2).Cmp(New(125, -1)), 0),
+		is.EqualTo(New(1250, -2).Cmp(New(126, -1)), -1),
+		is.EqualTo(New(1250, -2).Neg().String(), "-12.50"),
+		is.EqualTo(New(1200, -2).IsInt(), true),
+		is.EqualTo(New(1250, -2).IsInt(), false),
+		is.EqualTo(New(1250, -2).Rat().RatString(), "25/2"),
+	)
+
+	i, ok := New(300, -2).Int64()
+	expect.That(t, is.EqualTo(i, int64(3)), is.EqualTo(ok, true))
+
+	_, ok = New(301, -2).Int64()
+	expect.That(t, is.EqualTo(ok, false))
+}
+
+func TestContext_Round(t *testing.T) {
+	type testCase struct {
+		in   string
+		mode RoundingMode
+		want string
+	}
+
+	tests := []testCase{
+		{in: "2.345", mode: HalfEven, want: "2.34"},
+		{in: "2.355", mode: HalfEven, want: "2.36"},
+		{in: "2.3451", mode: HalfEven, want: "2.35"},
+		{in: "-2.345", mode: HalfEven, want: "-2.34"},
+		{in: "2.345", mode: HalfUp, want: "2.35"},
+		{in: "2.344", mode: HalfUp, want: "2.34"},
+		{in: "-2.345", mode: HalfUp, want: "-2.35"},
+		{in: "2.349", mode: Down, want: "2.34"},
+		{in: "-2.349", mode: Down, want: "-2.34"},
+		{in: "2.341", mode: Ceiling, want: "2.35"},
+		{in: "-2.349", mode: Ceiling, want: "-2.34"},
+		{in: "2.349", mode: Floor, want: "2.34"},
+		{in: "-2.341", mode: Floor, want: "-2.35"},
+		{in: "2.3", mode: Floor, want: "2.30"},
+		{in: "7", mode: Floor, want: "7.00"},
+		{in: "0.001", mode: Ceiling, want: "0.01"},
+		{in: "-0.001", mode: Ceiling, want: "0.00"},
+	}
+
+	for _, test := range tests {
+		d, err := Parse(test.in)
+		expect.That(t, is.NoError(err))
+
+		got := Context{Scale: 2, Rounding: test.mode}.Round(d)
+
+		expect.WithMessage(t, "in: %s, mode: %s", test.in, test.mode).That(
+			is.EqualTo(got.String(), test.want),
+		)
+	}
+}
+
+func TestContext(t *testing.T) {
+	c := Context{Scale: 2, Rounding: HalfEven}
+
+	expect.That(t,
+		is.EqualTo(c.Add(New(10, -1), New(20, -1)).String(), "3.00"),
+		is.EqualTo(c.Sub(New(1, 0), New(99, -2)).String(), "0.01"),
+		is.EqualTo(c.Mul(New(1999, -2), New(3, 0)).String(), "59.97"),
+		is.EqualTo(c.Mul(New(119, -2), New(19, -2)).String(), "0.23"),
+		is.EqualTo(c.Quo(New(1, 0), New(3, 0)).String(), "0.33"),
+		is.EqualTo(c.Quo(New(2, 0), New(3, 0)).String(), "0.67"),
+		is.EqualTo(c.Quo(New(-2, 0), New(3, 0)).String(), "-0.67"),
+		is.EqualTo(c.Quo(New(1, 0), New(8, 0)).String(), "0.12"),
+		is.EqualTo(c.Quo(New(100, 0), New(4, -1)).String(), "250.00"),
+		is.EqualTo(c.Quo(New(1, -3), New(1, 2)).String(), "0.00"),
+		is.EqualTo(c.Pow(New(15, -1), 3).String(), "3.38"),
+		is.EqualTo(c.Pow(New(2, 0), -2).String(), "0.25"),
+		is.EqualTo(c.Pow(New(3, 0), 0).String(), "1.00"),
+		is.EqualTo(c.Pow(New(200, -2), 10).String(), "1024.00"),
+		is.EqualTo(c.Pow(New(100, 0), 2).String(), "10000.00"),
+		is.EqualTo(c.Pow(New(100, -2), 1<<30).String(), "1.00"),
+		is.EqualTo(c.Pow(New(0, -2), 1<<30).String(), "0.00"),
+	)
+
+	up := Context{Scale: 2, Rounding: HalfUp}
+	expect.That(t,
+		is.EqualTo(up.Quo(New(1, 0), New(8, 0)).String(), "0.13"),
+		is.EqualTo(Context{Scale: 4, Rounding: Floor}.Quo(New(-1, 0), New(3, 0)).String(), "-0.3334"),
+		is.EqualTo(Context{Scale: 4, Rounding: Ceiling}.Quo(New(-1, 0), New(3, 0)).String(), "-0.3333"),
+	)
+}
+
+func TestParseRoundingMode(t *testing.T) {
+	for m := HalfEven; m <= Floor; m++ {
+		got, err := ParseRoundingMode(m.String())
+		expect.That(t, is.NoError(err), is.EqualTo(got, m))
+	}
+
+	_, err := ParseRoundingMode("sideways")
+	expect.That(t, is.EqualTo(err != nil, true))
+}
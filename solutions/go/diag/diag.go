@@ -0,0 +1,167 @@
+// Package diag renders errors returned by calc as compiler-style diagnostics. A diagnostic shows an error
+// code and message, an excerpt of the offending source line with the erroneous span underlined and a hint on
+// how to fix the error.
+//
+// Use FromError to create a Diagnostic from an error and a Renderer to write it, i.e.
+//
+//	_, err := calc.Eval(bytes.NewReader(src))
+//	if err != nil {
+//		diag.TextRenderer{}.Render(os.Stderr, "input", bytes.NewReader(src), diag.FromError(err))
+//	}
+package diag
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/halimath/calc"
+	"github.com/halimath/calc/syntax"
+	"github.com/halimath/calc/token"
+)
+
+// Pos describes a position in the input.
+type Pos = token.Pos
+
+// Span describes a contiguous range of input.
+type Span = token.Span
+
+// Code identifies the kind of error a Diagnostic reports.
+type Code string
+
+const (
+	CodeInvalidInput   Code = "E0000"
+	CodeInvalidRune    Code = "E0001"
+	CodeInvalidSyntax  Code = "E0002"
+	CodeDivisionByZero Code = "E0003"
+	CodeDomain         Code = "E0004"
+	CodeUnsupported    Code = "E0005"
+	CodeMissingOperand Code = "E0006"
+	CodeUndefined      Code = "E0007"
+	CodeCall           Code = "E0008"
+)
+
+// Note is a secondary message referring to a span of input, such as the opening parenthesis of an unclosed
+// one.
+type Note struct {
+	Span    Span
+	Message string
+}
+
+// Diagnostic describes an error in a way suitable for being presented to users.
+type Diagnostic struct {
+	Code    Code
+	Message string
+	// Span is the location of the error. It is the zero value if the error's location is unknown.
+	Span Span
+	// Label is a short description shown next to the underlined span.
+	Label string
+	Notes []Note
+	Hint  string
+}
+
+// kinds maps error sentinels to the code, label and hint to use. It is ordered from the most specific to
+// the most general sentinel.
+var kinds = []struct {
+	err   error
+	code  Code
+	label string
+	hint  string
+}{
+	{syntax.ErrScanFailed, CodeInvalidRune, "invalid character", "expressions consist of numbers, names, the operators + - * / ^, commas and parenthesis"},
+	{syntax.ErrInvalidSyntax, CodeInvalidSyntax, "unexpected token", "operators must be placed between two operands"},
+	{calc.ErrDivisionByZero, CodeDivisionByZero, "division by zero", "the right operand of / must not evaluate to 0"},
+	{calc.ErrDomain, CodeDomain, "result is not a finite real number", "powers of negative numbers require integral exponents, 0 must not be raised to a negative power and functions such as sqrt or ln must be called within their domain"},
+	{calc.ErrUndefined, CodeUndefined, "undefined variable", "pass a value for every variable, define it as a constant or assign it before using it in a script"},
+	{calc.ErrUnknownFunction, CodeCall, "unknown function", "call one of the builtin functions sqrt, abs, min, max, floor, ceil, round, ln, log10, exp, sin, cos or tan or define the function"},
+	{calc.ErrArity, CodeCall, "wrong number of arguments", ""},
+	{errors.ErrUnsupported, CodeUnsupported, "not supported", "evaluate the expression using float64 numbers"},
+	{calc.ErrInvalidInput, CodeInvalidInput, "invalid input", ""},
+}
+
+// scanKinds defines labels and hints for ScanErrors more specific than those defined by kinds.
+var scanKinds = map[calc.ScanErrorKind]struct {
+	label string
+	hint  string
+}{
+	calc.MalformedNumber:   {"malformed number", "numbers consist of digits with at most one decimal point"},
+	calc.ReadFailed:        {"failed to read input", ""},
+	calc.LeadingZero:       {"leading zero", "remove the leading zeros"},
+	calc.LeadingDot:        {"missing integer part", "write 0.5 instead of .5"},
+	calc.TrailingDot:       {"missing fractional digits", "add a digit after the decimal point or remove it"},
+	calc.RepeatedDot:       {"second decimal point", "numbers contain at most one decimal point"},
+	calc.NonASCIIDigit:     {"non-ASCII digit", "use the digits 0 to 9"},
+	calc.InvalidWhitespace: {"invalid whitespace", "separate tokens using spaces, tabs or line breaks"},
+}
+
+// FromError creates a Diagnostic describing err, which should be an error returned from one of calc's
+// evaluation functions.
+func FromError(err error) Diagnostic {
+	d := Diagnostic{
+		Code:    CodeInvalidInput,
+		Message: err.Error(),
+	}
+
+	for _, k := range kinds {
+		if errors.Is(err, k.err) {
+			d.Code, d.Label, d.Hint = k.code, k.label, k.hint
+			break
+		}
+	}
+
+	var (
+		scanErr   *calc.ScanError
+		syntaxErr *calc.SyntaxError
+		evalErr   *calc.EvalError
+		undefErr  *calc.UndefinedError
+		callErr   *calc.CallError
+	)
+
+	switch {
+	case errors.As(err, &scanErr):
+		d.Span, d.Message = scanErr.Span, scanErr.Message()
+		if k, ok := scanKinds[scanErr.Kind]; ok {
+			d.Label, d.Hint = k.label, k.hint
+		}
+	case errors.As(err, &syntaxErr):
+		d.Span, d.Message = syntaxErr.Span, syntaxErr.Message()
+		switch {
+		case syntaxErr.Kind == calc.UnclosedParen:
+			d.Label = "expected )"
+			d.Notes = append(d.Notes, Note{Span: syntaxErr.Opening, Message: "unclosed '(' opened here"})
+			d.Hint = "add a closing ) for every ("
+		case syntaxErr.Kind == calc.MissingOperand:
+			d.Code, d.Label = CodeMissingOperand, "missing operand"
+		case len(syntaxErr.Expected) > 0:
+			d.Hint = "expected " + oneOf(syntaxErr.Expected)
+		}
+	case errors.As(err, &evalErr):
+		d.Span, d.Message = evalErr.Span, evalErr.Message()
+	case errors.As(err, &undefErr):
+		d.Span, d.Message = undefErr.Span, undefErr.Message()
+	case errors.As(err, &callErr):
+		d.Span, d.Message = callErr.Span, callErr.Message()
+	}
+
+	return d
+}
+
+// oneOf formats a list of expected tokens, such as `a number, "(" or "+"`.
+func oneOf(tokens []string) string {
+	quoted := make([]string, len(tokens))
+	for i, t := range tokens {
+		switch t {
+		case "number":
+			quoted[i] = "a number"
+		case "identifier":
+			quoted[i] = "an identifier"
+		default:
+			quoted[i] = strconv.Quote(t)
+		}
+	}
+
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
@@ -0,0 +1,197 @@
+package diag
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/halimath/calc"
+	"github.com/halimath/calc/engine"
+	"github.com/halimath/calc/syntax"
+	"github.com/halimath/expect"
+	"github.com/halimath/expect/is"
+)
+
+func TestFromError(t *testing.T) {
+	type testCase struct {
+		in    string
+		code  Code
+		msg   string
+		start Pos
+		notes int
+	}
+
+	tests := []testCase{
+		{in: "1 + #", code: CodeInvalidRune, msg: "scan failed: invalid input rune: #", start: Pos{Offset: 4, Line: 1, Column: 5}},
+		{in: "2 +\n  )", code: CodeInvalidSyntax, msg: "invalid syntax: unexpected \")\"", start: Pos{Offset: 6, Line: 2, Column: 3}},
+		{in: "(1 + 2", code: CodeInvalidSyntax, msg: "invalid syntax: expected ) but got end of input (unclosed ( at 1:1)", start: Pos{Offset: 6, Line: 1, Column: 7}, notes: 1},
+		{in: "1 / 0", code: CodeDivisionByZero, msg: "division by zero", start: Pos{Offset: 2, Line: 1, Column: 3}},
+		{in: "1 + sqr(2)", code: CodeCall, msg: "unknown function: sqr", start: Pos{Offset: 4, Line: 1, Column: 5}},
+		{in: "1 + ln(0)", code: CodeDomain, msg: "domain error: ln(0)", start: Pos{Offset: 4, Line: 1, Column: 5}},
+		{in: "2 * rate", code: CodeUndefined, msg: "undefined variable: rate", start: Pos{Offset: 4, Line: 1, Column: 5}},
+		{in: "(-2) ^ 0.5", code: CodeDomain, msg: "domain error: -2 ^ 0.5", start: Pos{Offset: 5, Line: 1, Column: 6}},
+	}
+
+	for _, test := range tests {
+		_, err := calc.Eval(strings.NewReader(test.in))
+		d := FromError(err)
+
+		expect.WithMessage(t, "in: %q", test.in).That(
+			is.EqualTo(d.Code, test.code),
+			is.EqualTo(d.Message, test.msg),
+			is.EqualTo(d.Span.Start, test.start),
+			is.SliceOfLen(d.Notes, test.notes),
+		)
+	}
+}
+
+func TestFromError_missingOperand(t *testing.T) {
+	_, err := engine.RPN{}.Eval(syntax.NewScanner(strings.NewReader("1 +")), nil)
+	d := FromError(err)
+
+	expect.That(t,
+		is.EqualTo(d.Code, CodeMissingOperand),
+		is.EqualTo(d.Message, "invalid syntax: missing operand for \"+\""),
+		is.EqualTo(d.Span.Start, Pos{Offset: 2, Line: 1, Column: 3}),
+	)
+}
+
+func TestFromError_unlocated(t *testing.T) {
+	d := FromError(errors.New("failed"))
+
+	expect.That(t,
+		is.EqualTo(d.Code, CodeInvalidInput),
+		is.EqualTo(d.Message, "failed"),
+		is.EqualTo(d.Span.Start.IsValid(), false),
+	)
+}
+
+func TestTextRenderer(t *testing.T) {
+	type testCase struct {
+		in   string
+		want string
+	}
+
+	tests := []testCase{
+		{
+			in: "1 +\n 2 / 0",
+			want: `error[E0003]: division by zero
+ --> input:2:4
+  |
+2 |  2 / 0
+  |    ^ division by zero
+  |
+  = hint: the right operand of / must not evaluate to 0
+`,
+		},
+		{
+			in: "(1 + (2 * 3)",
+			want: `error[E0002]: invalid syntax: expected ) but got end of input (unclosed ( at 1:1)
+ --> input:1:13
+  |
+1 | (1 + (2 * 3)
+  |             ^ expected )
+  |
+1 | (1 + (2 * 3)
+  | - unclosed '(' opened here
+  |
+  = hint: add a closing ) for every (
+`,
+		},
+		{
+			in: "1 +\t12#",
+			want: `error[E0001]: scan failed: invalid input rune: #
+ --> input:1:7
+  |
+1 | 1 + 12#
+  |       ^ invalid character
+  |
+  = hint: expressions consist of numbers, names, the operators + - * / ^, commas and parenthesis
+`,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := calc.Eval(strings.NewReader(test.in))
+
+		var b strings.Builder
+		rerr := TextRenderer{}.Render(&b, "input", strings.NewReader(test.in), FromError(err))
+
+		expect.WithMessage(t, "in: %q", test.in).That(
+			is.NoError(rerr),
+			is.EqualTo(b.String(), test.want),
+		)
+	}
+}
+
+func TestTextRenderer_longLine(t *testing.T) {
+	in := strings.Repeat("1 + ", 1000) + "#" + strings.Repeat(" + 1", 1000)
+	_, err := calc.Eval(strings.NewReader(in))
+
+	var b strings.Builder
+	rerr := TextRenderer{Width: 20}.Render(&b, "input", strings.NewReader(in), FromError(err))
+
+	expect.That(t,
+		is.NoError(rerr),
+		is.StringContaining(b.String(), "1 | ...+ 1 + 1 + # + 1 + 1 ...\n  |              ^ invalid character\n"),
+	)
+}
+
+func TestTextRenderer_emptySpan(t *testing.T) {
+	// Numbers scanned in ValuesOnly mode have no end, so the renderer underlines the token at the start.
+	in := "2 * 12.5"
+	pos := Pos{Offset: 4, Line: 1, Column: 5}
+
+	var b strings.Builder
+	rerr := TextRenderer{}.Render(&b, "input", strings.NewReader(in), Diagnostic{Code: CodeDomain, Message: "failed", Span: Span{Start: pos, End: pos}})
+
+	expect.That(t,
+		is.NoError(rerr),
+		is.StringContaining(b.String(), "1 | 2 * 12.5\n  |     ^^^^\n"),
+	)
+}
+
+func TestANSIRenderer(t *testing.T) {
+	in := "1 / 0"
+	_, err := calc.Eval(strings.NewReader(in))
+
+	var b strings.Builder
+	rerr := ANSIRenderer{}.Render(&b, "input", strings.NewReader(in), FromError(err))
+
+	expect.That(t,
+		is.NoError(rerr),
+		is.StringContaining(b.String(), "\x1b[1;31merror[E0003]\x1b[0m"),
+		is.StringContaining(b.String(), "\x1b[1;31m^ division by zero\x1b[0m"),
+	)
+}
+
+func TestExcerpt(t *testing.T) {
+	type testCase struct {
+		src   string
+		pos   Pos
+		width int
+		want  string
+		col   int
+	}
+
+	tests := []testCase{
+		{src: "12345", pos: Pos{Offset: 2}, width: 10, want: "12345", col: 2},
+		{src: "ab\r\ncd\nef", pos: Pos{Offset: 5}, width: 10, want: "cd", col: 1},
+		{src: "ab\r\n", pos: Pos{Offset: 1}, width: 10, want: "ab", col: 1},
+		{src: "0123456789", pos: Pos{Offset: 5}, width: 4, want: "...3456...", col: 5},
+		{src: "0123456789", pos: Pos{Offset: 1}, width: 4, want: "0123...", col: 1},
+		{src: "0123456789", pos: Pos{Offset: 9}, width: 4, want: "...6789", col: 6},
+		{src: "äöüäöü", pos: Pos{Offset: 6}, width: 10, want: "äöüäöü", col: 3},
+		{src: "", pos: Pos{Offset: 0}, width: 10, want: "", col: 0},
+	}
+
+	for _, test := range tests {
+		got, col, err := excerpt(strings.NewReader(test.src), test.pos, test.width)
+
+		expect.WithMessage(t, "src: %q", test.src).That(
+			is.NoError(err),
+			is.EqualTo(got, test.want),
+			is.EqualTo(col, test.col),
+		)
+	}
+}
@@ -0,0 +1,225 @@
+package diag
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/halimath/calc/syntax"
+)
+
+// DefaultWidth is the maximum number of runes shown of a source line if a renderer's Width is 0.
+const DefaultWidth = 80
+
+// Renderer defines the interface for types that render diagnostics.
+type Renderer interface {
+	// Render writes d to w. name describes the source, i.e. a file name, and src provides random access to
+	// the source in order to show an excerpt of the offending line.
+	Render(w io.Writer, name string, src io.ReaderAt, d Diagnostic) error
+}
+
+// TextRenderer renders diagnostics as plain text.
+type TextRenderer struct {
+	// Width limits the number of runes shown of a source line. Longer lines are cut to a window around the
+	// span. 0 selects DefaultWidth.
+	Width int
+}
+
+func (r TextRenderer) Render(w io.Writer, name string, src io.ReaderAt, d Diagnostic) error {
+	return render(w, name, src, d, r.Width, palette{})
+}
+
+// ANSIRenderer renders diagnostics as text colored using ANSI escape sequences, suitable for terminals.
+type ANSIRenderer struct {
+	// Width limits the number of runes shown of a source line. Longer lines are cut to a window around the
+	// span. 0 selects DefaultWidth.
+	Width int
+}
+
+func (r ANSIRenderer) Render(w io.Writer, name string, src io.ReaderAt, d Diagnostic) error {
+	return render(w, name, src, d, r.Width, palette{
+		severity:  "\x1b[1;31m",
+		message:   "\x1b[1m",
+		gutter:    "\x1b[1;34m",
+		primary:   "\x1b[1;31m",
+		secondary: "\x1b[1;34m",
+		hint:      "\x1b[1;36m",
+		reset:     "\x1b[0m",
+	})
+}
+
+// palette defines the escape sequences used to style the parts of a diagnostic.
+type palette struct {
+	severity, message, gutter, primary, secondary, hint, reset string
+}
+
+func render(w io.Writer, name string, src io.ReaderAt, d Diagnostic, width int, p palette) error {
+	if width <= 0 {
+		width = DefaultWidth
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%serror[%s]%s%s: %s%s\n", p.severity, d.Code, p.reset, p.message, d.Message, p.reset)
+
+	if !d.Span.Start.IsValid() {
+		if d.Hint != "" {
+			fmt.Fprintf(&b, "  %s= hint:%s %s\n", p.hint, p.reset, d.Hint)
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	lineNumbers := []int{d.Span.Start.Line}
+	for _, n := range d.Notes {
+		lineNumbers = append(lineNumbers, n.Span.Start.Line)
+	}
+	gutter := 0
+	for _, l := range lineNumbers {
+		gutter = max(gutter, len(strconv.Itoa(l)))
+	}
+	pad := strings.Repeat(" ", gutter)
+
+	fmt.Fprintf(&b, "%s%s-->%s %s:%s\n", pad, p.gutter, p.reset, name, d.Span.Start)
+	fmt.Fprintf(&b, "%s %s|%s\n", pad, p.gutter, p.reset)
+
+	snippet := func(span Span, marker byte, style, label string) error {
+		line, col, err := excerpt(src, span.Start, width)
+		if err != nil {
+			return err
+		}
+
+		length := 1
+		if span.End.Line == span.Start.Line && span.End.Column > span.Start.Column {
+			length = span.End.Column - span.Start.Column
+		} else if n := tokenLength(line, col); n > 0 && span.End.Offset <= span.Start.Offset {
+			// The span is empty because its end has not been derived, such as for a number scanned in
+			// ValuesOnly mode, so underline the token starting at it.
+			length = n
+		}
+		length = max(1, min(length, utf8.RuneCountInString(line)-col))
+
+		fmt.Fprintf(&b, "%s%*d |%s %s\n", p.gutter, gutter, span.Start.Line, p.reset, line)
+		fmt.Fprintf(&b, "%s %s|%s %s%s%s", pad, p.gutter, p.reset, strings.Repeat(" ", col), style, strings.Repeat(string(marker), length))
+		if label != "" {
+			fmt.Fprintf(&b, " %s", label)
+		}
+		fmt.Fprintf(&b, "%s\n", p.reset)
+
+		return nil
+	}
+
+	if err := snippet(d.Span, '^', p.primary, d.Label); err != nil {
+		return err
+	}
+
+	for _, n := range d.Notes {
+		fmt.Fprintf(&b, "%s %s|%s\n", pad, p.gutter, p.reset)
+		if err := snippet(n.Span, '-', p.secondary, n.Message); err != nil {
+			return err
+		}
+	}
+
+	if d.Hint != "" {
+		fmt.Fprintf(&b, "%s %s|%s\n", pad, p.gutter, p.reset)
+		fmt.Fprintf(&b, "%s %s= hint:%s %s\n", pad, p.hint, p.reset, d.Hint)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ellipsis marks a source line that has been cut.
+const ellipsis = "..."
+
+// excerpt reads the line containing pos from src and returns it along with the number of runes preceding
+// pos. Lines longer than width runes are cut to a window around pos. Only the window is read from src, so
+// excerpts of arbitrarily long lines are cheap.
+func excerpt(src io.ReaderAt, pos Pos, width int) (string, int, error) {
+	budget := width * utf8.UTFMax
+
+	start := max(0, pos.Offset-budget)
+	before := make([]byte, pos.Offset-start)
+	if _, err := src.ReadAt(before, int64(start)); err != nil && !errors.Is(err, io.EOF) {
+		return "", 0, err
+	}
+	cutLeft := start > 0
+	if i := bytes.LastIndexByte(before, '\n'); i >= 0 {
+		before = before[i+1:]
+		cutLeft = false
+	}
+	for len(before) > 0 && !utf8.RuneStart(before[0]) {
+		before = before[1:]
+	}
+
+	after := make([]byte, budget)
+	n, err := src.ReadAt(after, int64(pos.Offset))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", 0, err
+	}
+	after = after[:n]
+	cutRight := n == budget
+	if i := bytes.IndexByte(after, '\n'); i >= 0 {
+		after = after[:i]
+		cutRight = false
+	}
+	after = bytes.TrimSuffix(after, []byte{'\r'})
+
+	left, right := runes(before), runes(after)
+
+	keepLeft := min(len(left), width/2)
+	keepRight := min(len(right), width-keepLeft)
+	keepLeft = min(len(left), width-keepRight)
+
+	if keepLeft < len(left) {
+		left, cutLeft = left[len(left)-keepLeft:], true
+	}
+	if keepRight < len(right) {
+		right, cutRight = right[:keepRight], true
+	}
+
+	var b strings.Builder
+	col := len(left)
+	if cutLeft {
+		b.WriteString(ellipsis)
+		col += len(ellipsis)
+	}
+	b.WriteString(string(left))
+	b.WriteString(string(right))
+	if cutRight {
+		b.WriteString(ellipsis)
+	}
+
+	return b.String(), col, nil
+}
+
+// tokenLength returns the number of runes of the token starting at the rune col of line, or 0 if no token
+// starts there.
+func tokenLength(line string, col int) int {
+	r := []rune(line)
+	if col >= len(r) {
+		return 0
+	}
+	rest := string(r[col:])
+
+	tok, err := syntax.NewScanner(strings.NewReader(rest)).Next()
+	if err != nil || tok.Offset != 0 {
+		return 0
+	}
+	return utf8.RuneCountInString(rest[:tok.End()])
+}
+
+// runes decodes b replacing tabs with a single space, so that columns align with the markers below.
+func runes(b []byte) []rune {
+	r := []rune(string(b))
+	for i := range r {
+		if r[i] == '\t' {
+			r[i] = ' '
+		}
+	}
+	return r
+}
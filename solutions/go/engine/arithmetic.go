@@ -0,0 +1,307 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/halimath/calc/decimal"
+	"github.com/halimath/calc/token"
+)
+
+// arithmetic defines the operations needed by the engines to calculate with numbers of type T.
+type arithmetic[T any] interface {
+	// number converts the Number token tok to a T.
+	number(tok token.Token) (T, error)
+
+	// apply applies the binary operator op to l and r.
+	apply(op token.Type, l, r T) (T, error)
+
+	// neg returns -v.
+	neg(v T) T
+
+	// call calls the builtin function f with args. The number of arguments has been checked already.
+	call(f *function, args []T) (T, error)
+}
+
+// floatArithmetic implements arithmetic using float64 values.
+type floatArithmetic struct{}
+
+func (floatArithmetic) number(tok token.Token) (float64, error) { return tok.Value, nil }
+
+func (floatArithmetic) neg(v float64) float64 { return -v }
+
+func (floatArithmetic) call(f *function, args []float64) (float64, error) { return f.call(args) }
+
+func (floatArithmetic) apply(op token.Type, l, r float64) (float64, error) {
+	switch op {
+	case token.Add:
+		return l + r, nil
+	case token.Sub:
+		return l - r, nil
+	case token.Mul:
+		return l * r, nil
+	case token.Div:
+		if r == 0 {
+			return 0, ErrDivisionByZero
+		}
+		return l / r, nil
+	case token.Pow:
+		return pow(l, r)
+	default:
+		return 0, fmt.Errorf("%w: unexpected operator: %v", ErrInvalidInput, op)
+	}
+}
+
+// pow calculates l ^ r. It reports ErrDomain for any combination of l and r for which the power is not a
+// finite real number, such as a negative base with a fractional exponent or 0 with a negative exponent.
+func pow(l, r float64) (float64, error) {
+	if l == 0 && r < 0 {
+		return 0, fmt.Errorf("%w: 0 ^ %v", ErrDomain, r)
+	}
+
+	if l < 0 && r != math.Trunc(r) {
+		return 0, fmt.Errorf("%w: %v ^ %v", ErrDomain, l, r)
+	}
+
+	v := math.Pow(l, r)
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return 0, fmt.Errorf("%w: %v ^ %v is out of range", ErrDomain, l, r)
+	}
+
+	return v, nil
+}
+
+// bigFloatArithmetic implements arithmetic using big.Float values with a mantissa of prec bits. prec must
+// not be 0.
+type bigFloatArithmetic struct {
+	prec uint
+}
+
+func (a bigFloatArithmetic) number(tok token.Token) (*big.Float, error) {
+	v, _, err := big.ParseFloat(tok.Literal, 10, a.prec, big.ToNearestEven)
+	return v, err
+}
+
+func (a bigFloatArithmetic) neg(v *big.Float) *big.Float { return a.new().Neg(v) }
+
+func (a bigFloatArithmetic) call(f *function, args []*big.Float) (*big.Float, error) {
+	return callOrdered(f, args, (*big.Float).Cmp, a.neg)
+}
+
+func (a bigFloatArithmetic) apply(op token.Type, l, r *big.Float) (*big.Float, error) {
+	switch op {
+	case token.Add:
+		return a.checked(a.new().Add(l, r), op, l, r)
+	case token.Sub:
+		return a.checked(a.new().Sub(l, r), op, l, r)
+	case token.Mul:
+		return a.checked(a.new().Mul(l, r), op, l, r)
+	case token.Div:
+		if r.Sign() == 0 {
+			return nil, ErrDivisionByZero
+		}
+		return a.checked(a.new().Quo(l, r), op, l, r)
+	case token.Pow:
+		return a.pow(l, r)
+	default:
+		return nil, fmt.Errorf("%w: unexpected operator: %v", ErrInvalidInput, op)
+	}
+}
+
+// pow calculates l ^ r for an integral exponent r by repeated squaring.
+func (a bigFloatArithmetic) pow(l, r *big.Float) (*big.Float, error) {
+	if !r.IsInt() {
+		return nil, fmt.Errorf("%w: fractional exponent %s", errors.ErrUnsupported, r.Text('g', -1))
+	}
+
+	if l.Sign() == 0 && r.Sign() < 0 {
+		return nil, fmt.Errorf("%w: 0 ^ %s", ErrDomain, r.Text('g', -1))
+	}
+
+	e, acc := r.Int64()
+	if acc != big.Exact || e < math.MinInt32 || e > math.MaxInt32 {
+		return nil, fmt.Errorf("%w: %s ^ %s is out of range", ErrDomain, l.Text('g', -1), r.Text('g', -1))
+	}
+
+	neg := e < 0
+	if neg {
+		e = -e
+	}
+
+	result := a.new().SetInt64(1)
+	base := a.new().Set(l)
+	for {
+		if e&1 == 1 {
+			result.Mul(result, base)
+		}
+		e >>= 1
+		if e == 0 {
+			break
+		}
+		base.Mul(base, base)
+
+		if base.IsInf() || result.IsInf() {
+			return nil, fmt.Errorf("%w: %s ^ %s is out of range", ErrDomain, l.Text('g', -1), r.Text('g', -1))
+		}
+	}
+
+	if result.IsInf() {
+		return nil, fmt.Errorf("%w: %s ^ %s is out of range", ErrDomain, l.Text('g', -1), r.Text('g', -1))
+	}
+
+	if neg {
+		// The reciprocal is infinite if l ^ -r underflowed to 0 or is too small for it to be in range.
+		result.Quo(a.new().SetInt64(1), result)
+		if result.IsInf() {
+			return nil, fmt.Errorf("%w: %s ^ %s is out of range", ErrDomain, l.Text('g', -1), r.Text('g', -1))
+		}
+	}
+
+	return result, nil
+}
+
+// checked returns v unless it overflowed the exponent range of big.Float.
+func (a bigFloatArithmetic) checked(v *big.Float, op token.Type, l, r *big.Float) (*big.Float, error) {
+	if v.IsInf() {
+		return nil, fmt.Errorf("%w: result of %s %v %s is out of range", ErrDomain, l.Text('g', -1), op, r.Text('g', -1))
+	}
+	return v, nil
+}
+
+func (a bigFloatArithmetic) new() *big.Float { return new(big.Float).SetPrec(a.prec) }
+
+// ratArithmetic implements arithmetic using exact big.Rat values.
+type ratArithmetic struct{}
+
+func (ratArithmetic) number(tok token.Token) (*big.Rat, error) {
+	v, ok := new(big.Rat).SetString(tok.Literal)
+	if !ok {
+		return nil, fmt.Errorf("invalid number literal: %q", tok.Literal)
+	}
+	return v, nil
+}
+
+func (ratArithmetic) neg(v *big.Rat) *big.Rat { return new(big.Rat).Neg(v) }
+
+func (a ratArithmetic) call(f *function, args []*big.Rat) (*big.Rat, error) {
+	return callOrdered(f, args, (*big.Rat).Cmp, a.neg)
+}
+
+func (a ratArithmetic) apply(op token.Type, l, r *big.Rat) (*big.Rat, error) {
+	switch op {
+	case token.Add:
+		return new(big.Rat).Add(l, r), nil
+	case token.Sub:
+		return new(big.Rat).Sub(l, r), nil
+	case token.Mul:
+		return new(big.Rat).Mul(l, r), nil
+	case token.Div:
+		if r.Sign() == 0 {
+			return nil, ErrDivisionByZero
+		}
+		return new(big.Rat).Quo(l, r), nil
+	case token.Pow:
+		return a.pow(l, r)
+	default:
+		return nil, fmt.Errorf("%w: unexpected operator: %v", ErrInvalidInput, op)
+	}
+}
+
+// pow calculates l ^ r for an integral exponent r. Powers with a fractional exponent are not rational in
+// general and thus not supported.
+func (ratArithmetic) pow(l, r *big.Rat) (*big.Rat, error) {
+	if !r.IsInt() {
+		return nil, fmt.Errorf("%w: fractional exponent %s", errors.ErrUnsupported, r.RatString())
+	}
+
+	if l.Sign() == 0 && r.Sign() < 0 {
+		return nil, fmt.Errorf("%w: 0 ^ %s", ErrDomain, r.RatString())
+	}
+
+	e := r.Num()
+	if !e.IsInt64() || e.Int64() < math.MinInt32 || e.Int64() > math.MaxInt32 || powTooLarge(l, e.Int64()) {
+		return nil, fmt.Errorf("%w: %s ^ %s is out of range", ErrDomain, l.RatString(), r.RatString())
+	}
+
+	abs := new(big.Int).Abs(e)
+	num := new(big.Int).Exp(l.Num(), abs, nil)
+	den := new(big.Int).Exp(l.Denom(), abs, nil)
+	if e.Sign() < 0 {
+		num, den = den, num
+	}
+
+	return new(big.Rat).SetFrac(num, den), nil
+}
+
+// maxPowBits limits the size of the numerator and denominator of powers calculated using exact numbers, which
+// is about 315,000 decimal digits. Larger powers would take ages to calculate and exhaust memory.
+const maxPowBits = 1 << 20
+
+// powTooLarge reports whether the numerator or denominator of x ^ e would have more than maxPowBits bits.
+func powTooLarge(x *big.Rat, e int64) bool {
+	// A number of n bits is at least 2^(n-1), so its power to e has at least (n-1)*|e| bits.
+	bits := int64(max(x.Num().BitLen(), x.Denom().BitLen()) - 1)
+	if e < 0 {
+		e = -e
+	}
+	return bits > 0 && e > maxPowBits/bits
+}
+
+// decimalArithmetic implements arithmetic using decimal.Decimal values with the scale and rounding of ctx.
+type decimalArithmetic struct {
+	ctx decimal.Context
+}
+
+func (a decimalArithmetic) number(tok token.Token) (decimal.Decimal, error) {
+	v, err := decimal.Parse(tok.Literal)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	return a.ctx.Round(v), nil
+}
+
+func (decimalArithmetic) neg(v decimal.Decimal) decimal.Decimal { return v.Neg() }
+
+func (a decimalArithmetic) call(f *function, args []decimal.Decimal) (decimal.Decimal, error) {
+	return callOrdered(f, args, decimal.Decimal.Cmp, a.neg)
+}
+
+func (a decimalArithmetic) apply(op token.Type, l, r decimal.Decimal) (decimal.Decimal, error) {
+	switch op {
+	case token.Add:
+		return a.ctx.Add(l, r), nil
+	case token.Sub:
+		return a.ctx.Sub(l, r), nil
+	case token.Mul:
+		return a.ctx.Mul(l, r), nil
+	case token.Div:
+		if r.Sign() == 0 {
+			return decimal.Decimal{}, ErrDivisionByZero
+		}
+		return a.ctx.Quo(l, r), nil
+	case token.Pow:
+		return a.pow(l, r)
+	default:
+		return decimal.Decimal{}, fmt.Errorf("%w: unexpected operator: %v", ErrInvalidInput, op)
+	}
+}
+
+// pow calculates l ^ r for an integral exponent r. Powers with a fractional exponent are not supported.
+func (a decimalArithmetic) pow(l, r decimal.Decimal) (decimal.Decimal, error) {
+	if !r.IsInt() {
+		return decimal.Decimal{}, fmt.Errorf("%w: fractional exponent %s", errors.ErrUnsupported, r)
+	}
+
+	if l.Sign() == 0 && r.Sign() < 0 {
+		return decimal.Decimal{}, fmt.Errorf("%w: 0 ^ %s", ErrDomain, r)
+	}
+
+	e, ok := r.Int64()
+	if !ok || e < math.MinInt32 || e > math.MaxInt32 || powTooLarge(l.Rat(), e) {
+		return decimal.Decimal{}, fmt.Errorf("%w: %s ^ %s is out of range", ErrDomain, l, r)
+	}
+
+	return a.ctx.Pow(l, int(e)), nil
+}
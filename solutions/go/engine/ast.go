@@ -0,0 +1,282 @@
+package engine
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/halimath/calc/ast"
+	"github.com/halimath/calc/decimal"
+	"github.com/halimath/calc/syntax"
+	"github.com/halimath/calc/token"
+)
+
+// AST is an Engine parsing expressions into an abstract syntax tree using a syntax.Parser and evaluating the
+// tree. It is the default engine. The tree takes memory proportional to the length of the input.
+type AST struct{}
+
+func (AST) Name() string { return "ast" }
+
+func (AST) Eval(s *syntax.Scanner, env *Env) (float64, error) {
+	s.ValuesOnly()
+	return evalParsed[float64](floatArithmetic{}, s, env)
+}
+
+func (AST) EvalBig(s *syntax.Scanner, prec uint) (*big.Float, error) {
+	return evalParsed[*big.Float](bigFloatArithmetic{prec: bigPrec(prec)}, s, nil)
+}
+
+func (AST) EvalRat(s *syntax.Scanner) (*big.Rat, error) {
+	return evalParsed[*big.Rat](ratArithmetic{}, s, nil)
+}
+
+func (AST) EvalDecimal(s *syntax.Scanner, ctx decimal.Context) (decimal.Decimal, error) {
+	return evalParsed[decimal.Decimal](decimalArithmetic{ctx: ctx}, s, nil)
+}
+
+func (AST) Compile(s *syntax.Scanner, env *Env) (*Program, error) {
+	s.ValuesOnly()
+	root, err := syntax.NewParser(s).Expr()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+
+	return CompileTree(root, env)
+}
+
+func (AST) Check(s *syntax.Scanner, max int) error {
+	p := syntax.NewParser(s)
+	p.Recover(max)
+	_, err := p.Expr()
+	return err
+}
+
+func (AST) Run(s *syntax.Scanner, env *Env) ([]float64, error) {
+	s.Script()
+	s.ValuesOnly()
+	prog, err := syntax.NewParser(s).Program()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+
+	return RunTree(prog, env)
+}
+
+func (AST) CheckScript(s *syntax.Scanner, max int) error {
+	s.Script()
+	p := syntax.NewParser(s)
+	p.Recover(max)
+	_, err := p.Program()
+	return err
+}
+
+// EvalTree evaluates the tree rooted at root, which is usually created by a syntax.Parser, using float64
+// numbers. It reports an *UndefinedError for any variable.
+func EvalTree(root ast.Node) (float64, error) {
+	return eval[float64](floatArithmetic{}, root, nil)
+}
+
+// RunTree runs the script prog, which is usually created by a syntax.Parser, using float64 numbers and returns
+// the value of every statement. It calls the functions and uses the constants defined by env, which may be
+// nil.
+func RunTree(prog *ast.Program, env *Env) ([]float64, error) {
+	scope := env.scope()
+	values := make([]float64, 0, len(prog.Stmts))
+
+	for _, stmt := range prog.Stmts {
+		x := stmt
+		assign, ok := stmt.(ast.Assign)
+		if ok {
+			x = assign.X
+		}
+
+		v, err := eval[float64](floatArithmetic{}, x, scope)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			scope.consts[assign.Name] = v
+		}
+		values = append(values, v)
+	}
+
+	return values, nil
+}
+
+// CompileTree compiles the tree rooted at root, which is usually created by a syntax.Parser, into a Program
+// calling the functions and using the constants defined by env, which may be nil.
+func CompileTree(root ast.Node, env *Env) (*Program, error) {
+	type frame struct {
+		node   ast.Node
+		reduce bool
+	}
+
+	p := newProgram()
+	e := newEmitter()
+
+	// Emit the instructions in post-order using a heap allocated work stack just like eval does.
+	work := []frame{{node: root}}
+	for len(work) > 0 {
+		f := work[len(work)-1]
+		work = work[:len(work)-1]
+
+		switch n := f.node.(type) {
+		case ast.Number:
+			e.constant(n.Value, n.Span)
+
+		case ast.Ident:
+			if v, ok := env.constant(n.Name); ok {
+				e.constant(v, n.Span)
+			} else {
+				e.load(p.declare(n.Name, n.Span), n.Span)
+			}
+
+		case ast.Operator:
+			if !f.reduce {
+				work = append(work, frame{node: n, reduce: true}, frame{node: n.R}, frame{node: n.L})
+				continue
+			}
+			e.binary(opTypes[n.Op], n.Span)
+
+		case ast.Unary:
+			if !f.reduce {
+				work = append(work, frame{node: n, reduce: true}, frame{node: n.X})
+				continue
+			}
+			if n.Op == ast.Sub {
+				e.neg(n.Span)
+			}
+
+		case ast.Call:
+			if !f.reduce {
+				work = append(work, frame{node: n, reduce: true})
+				for i := len(n.Args) - 1; i >= 0; i-- {
+					work = append(work, frame{node: n.Args[i]})
+				}
+				continue
+			}
+
+			fn, err := env.function(n.Name, len(n.Args), n.Span)
+			if err != nil {
+				return nil, err
+			}
+			e.call(fn, len(n.Args), n.Span)
+
+		default:
+			return nil, fmt.Errorf("%w: unexpected ast node: %v", ErrInvalidInput, f.node)
+		}
+	}
+
+	p.code = e.b
+	return p, nil
+}
+
+// evalParsed parses the expression scanned by s and evaluates it using a and env.
+func evalParsed[T any](a arithmetic[T], s *syntax.Scanner, env *Env) (T, error) {
+	root, err := syntax.NewParser(s).Expr()
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+
+	return eval(a, root, env)
+}
+
+// opTypes maps the operators of a tree to the token types applied by arithmetic.
+var opTypes = [...]token.Type{
+	ast.Add: token.Add,
+	ast.Sub: token.Sub,
+	ast.Mul: token.Mul,
+	ast.Div: token.Div,
+	ast.Pow: token.Pow,
+}
+
+// eval evaluates the tree rooted at root using a. It walks the tree in post-order using heap allocated work
+// and value stacks instead of recursion, so the depth of the tree is not limited by the goroutine's stack.
+// It calls the functions and uses the constants defined by env, which may be nil. Variables are only supported
+// by compiled programs, so eval reports an *UndefinedError for any other identifier.
+func eval[T any](a arithmetic[T], root ast.Node, env *Env) (T, error) {
+	type frame struct {
+		node   ast.Node
+		reduce bool
+	}
+
+	var zero T
+
+	work := make([]frame, 0, 64)
+	work = append(work, frame{node: root})
+	values := make([]T, 0, 64)
+
+	for len(work) > 0 {
+		f := work[len(work)-1]
+		work = work[:len(work)-1]
+
+		switch n := f.node.(type) {
+		case ast.Number:
+			v, err := a.number(token.Token{Type: token.Number, Value: n.Value, Literal: n.Literal, Offset: n.Span.Start.Offset})
+			if err != nil {
+				return zero, fmt.Errorf("%w: %w", ErrInvalidInput, &syntax.ScanError{Kind: syntax.MalformedNumber, Span: n.Span, Text: n.Literal, Err: err})
+			}
+			values = append(values, v)
+
+		case ast.Ident:
+			c, ok := env.constant(n.Name)
+			if !ok {
+				return zero, &UndefinedError{Name: n.Name, Span: n.Span}
+			}
+			v, err := a.number(constToken(n.Name, c, n.Span.Start.Offset))
+			if err != nil {
+				return zero, err
+			}
+			values = append(values, v)
+
+		case ast.Operator:
+			if !f.reduce {
+				// Revisit n once both operands have been evaluated. L is pushed last so it gets evaluated
+				// first.
+				work = append(work, frame{node: n, reduce: true}, frame{node: n.R}, frame{node: n.L})
+				continue
+			}
+
+			l, r := values[len(values)-2], values[len(values)-1]
+			values = values[:len(values)-2]
+
+			v, err := a.apply(opTypes[n.Op], l, r)
+			if err != nil {
+				return zero, newEvalError(n.Span, n.Op, err, l, r)
+			}
+			values = append(values, v)
+
+		case ast.Unary:
+			if !f.reduce {
+				work = append(work, frame{node: n, reduce: true}, frame{node: n.X})
+				continue
+			}
+
+			if n.Op == ast.Sub {
+				values[len(values)-1] = a.neg(values[len(values)-1])
+			}
+
+		case ast.Call:
+			if !f.reduce {
+				work = append(work, frame{node: n, reduce: true})
+				for i := len(n.Args) - 1; i >= 0; i-- {
+					work = append(work, frame{node: n.Args[i]})
+				}
+				continue
+			}
+
+			base := len(values) - len(n.Args)
+			v, err := call(a, env, n.Name, n.Span, values[base:])
+			if err != nil {
+				return zero, err
+			}
+			values = append(values[:base], v)
+
+		default:
+			return zero, fmt.Errorf("%w: unexpected ast node: %v", ErrInvalidInput, f.node)
+		}
+	}
+
+	return values[0], nil
+}
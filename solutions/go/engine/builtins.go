@@ -0,0 +1,161 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/halimath/calc/token"
+)
+
+// function is a function that may be called from expressions, either a builtin or one registered with an Env.
+type function struct {
+	name string
+	// arity is the number of arguments the function expects. A variadic function expects at least arity
+	// arguments.
+	arity    int
+	variadic bool
+	// fn calculates the function using float64 numbers.
+	fn func(args []float64) (float64, error)
+}
+
+func (f *function) String() string { return f.name }
+
+// checkArity reports an error wrapping ErrArity unless f accepts n arguments.
+func (f *function) checkArity(n int) error {
+	switch {
+	case f.variadic && n < f.arity:
+		return fmt.Errorf("%w: %s expects at least %s but got %d", ErrArity, f.name, arguments(f.arity), n)
+	case !f.variadic && n != f.arity:
+		return fmt.Errorf("%w: %s expects %s but got %d", ErrArity, f.name, arguments(f.arity), n)
+	default:
+		return nil
+	}
+}
+
+// call calls f with args. Instead of returning NaN or an infinite value, it reports ErrDomain.
+func (f *function) call(args []float64) (float64, error) {
+	v, err := f.fn(args)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("%w: %s", ErrDomain, formatCall(f.name, args))
+	}
+	return v, nil
+}
+
+func arguments(n int) string {
+	if n == 1 {
+		return "1 argument"
+	}
+	return strconv.Itoa(n) + " arguments"
+}
+
+// formatCall formats a call of the function name with args, such as sqrt(-1).
+func formatCall(name string, args []float64) string {
+	var s strings.Builder
+	s.WriteString(name)
+	s.WriteByte('(')
+	for i, arg := range args {
+		if i > 0 {
+			s.WriteString(", ")
+		}
+		fmt.Fprint(&s, arg)
+	}
+	s.WriteByte(')')
+	return s.String()
+}
+
+// builtins contains the standard library of functions available to all expressions.
+var builtins = map[string]*function{}
+
+func init() {
+	unary := map[string]func(float64) float64{
+		"sqrt":  math.Sqrt,
+		"abs":   math.Abs,
+		"floor": math.Floor,
+		"ceil":  math.Ceil,
+		"round": math.Round,
+		"ln":    math.Log,
+		"log10": math.Log10,
+		"exp":   math.Exp,
+		"sin":   math.Sin,
+		"cos":   math.Cos,
+		"tan":   math.Tan,
+	}
+	for name, fn := range unary {
+		builtins[name] = &function{name: name, arity: 1, fn: func(args []float64) (float64, error) { return fn(args[0]), nil }}
+	}
+
+	builtins["min"] = &function{name: "min", arity: 1, variadic: true, fn: func(args []float64) (float64, error) {
+		v := args[0]
+		for _, arg := range args[1:] {
+			v = math.Min(v, arg)
+		}
+		return v, nil
+	}}
+	builtins["max"] = &function{name: "max", arity: 1, variadic: true, fn: func(args []float64) (float64, error) {
+		v := args[0]
+		for _, arg := range args[1:] {
+			v = math.Max(v, arg)
+		}
+		return v, nil
+	}}
+}
+
+// call calls the function name located at span with args using a, looking it up in env.
+func call[T any](a arithmetic[T], env *Env, name string, span token.Span, args []T) (T, error) {
+	var zero T
+
+	f, err := env.function(name, len(args), span)
+	if err != nil {
+		return zero, err
+	}
+
+	v, err := a.call(f, args)
+	if err != nil {
+		return zero, newEvalError(span, f, err, boxed(args)...)
+	}
+	return v, nil
+}
+
+// boxed returns args as a slice of any, as reported by EvalError.Operands.
+func boxed[T any](args []T) []any {
+	operands := make([]any, len(args))
+	for i, arg := range args {
+		operands[i] = arg
+	}
+	return operands
+}
+
+// callOrdered calls f for number types other than float64, which only support the builtins relying on
+// comparison and negation, i.e. abs, min and max. It reports errors.ErrUnsupported for all other functions.
+func callOrdered[T any](f *function, args []T, cmp func(l, r T) int, neg func(v T) T) (T, error) {
+	name := f.name
+	if builtins[name] != f {
+		// Functions registered with an Env calculate using float64 numbers only.
+		name = ""
+	}
+
+	switch name {
+	case "abs":
+		if n := neg(args[0]); cmp(n, args[0]) > 0 {
+			return n, nil
+		}
+		return args[0], nil
+	case "min", "max":
+		v := args[0]
+		for _, arg := range args[1:] {
+			if c := cmp(arg, v); (c < 0 && name == "min") || (c > 0 && name == "max") {
+				v = arg
+			}
+		}
+		return v, nil
+	default:
+		var zero T
+		return zero, fmt.Errorf("%w: function %s", errors.ErrUnsupported, f.name)
+	}
+}
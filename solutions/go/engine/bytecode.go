@@ -0,0 +1,219 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/halimath/calc/token"
+)
+
+// opcode identifies an instruction of the bytecode executed by a Program.
+type opcode uint8
+
+const (
+	// opConst pushes the constant at index arg of the constant pool.
+	opConst opcode = iota + 1
+	// opLoad pushes the value of the variable at index arg.
+	opLoad
+	// opNeg replaces the operand on top of the stack with its negation.
+	opNeg
+	// opAdd, opSub, opMul, opDiv and opPow pop the right and then the left operand and push the result of
+	// applying the operator to them.
+	opAdd
+	opSub
+	opMul
+	opDiv
+	opPow
+	// opCall pops the arguments of the call at index arg of the call pool and pushes the function's result.
+	opCall
+)
+
+func (o opcode) String() string {
+	switch o {
+	case opConst:
+		return "const"
+	case opLoad:
+		return "load"
+	case opNeg:
+		return "neg"
+	case opAdd:
+		return "+"
+	case opSub:
+		return "-"
+	case opMul:
+		return "*"
+	case opDiv:
+		return "/"
+	case opPow:
+		return "^"
+	case opCall:
+		return "call"
+	default:
+		return fmt.Sprintf("opcode(%d)", int(o))
+	}
+}
+
+// binaryOps maps the token types of binary operators to their opcodes.
+var binaryOps = [...]opcode{
+	token.Add: opAdd,
+	token.Sub: opSub,
+	token.Mul: opMul,
+	token.Div: opDiv,
+	token.Pow: opPow,
+}
+
+// instr is a single instruction. arg is only used by opConst, opLoad and opCall.
+type instr struct {
+	op  opcode
+	arg uint32
+}
+
+// bytecode is an expression compiled to instructions for a stack machine calculating with float64 numbers.
+type bytecode struct {
+	code   []instr
+	consts []float64
+	calls  []callSite
+	// spans contains the location of the input each instruction has been emitted for, used to report errors.
+	spans []token.Span
+	// depth is the maximum number of operands on the stack while running code.
+	depth int
+}
+
+func (b *bytecode) String() string {
+	var s strings.Builder
+	for _, in := range b.code {
+		switch in.op {
+		case opConst:
+			fmt.Fprintf(&s, "%s %v\n", in.op, b.consts[in.arg])
+		case opLoad:
+			fmt.Fprintf(&s, "%s %d\n", in.op, in.arg)
+		case opCall:
+			fmt.Fprintf(&s, "%s %s/%d\n", in.op, b.calls[in.arg].f, b.calls[in.arg].argc)
+		default:
+			fmt.Fprintf(&s, "%s\n", in.op)
+		}
+	}
+	return s.String()
+}
+
+// stackSize is the number of operands a run keeps on the goroutine's stack. Deeper programs allocate their
+// operand stack on the heap.
+const stackSize = 32
+
+// run executes b using vars as the values of the variables loaded by opLoad. The code must be valid, i.e.
+// contain enough operands for every operator and leave at least one value on the stack.
+func (b *bytecode) run(vars []float64) (float64, error) {
+	var buf [stackSize]float64
+	s := buf[:]
+	if b.depth > len(buf) {
+		s = make([]float64, b.depth)
+	}
+
+	sp := 0
+	for i, in := range b.code {
+		switch in.op {
+		case opConst:
+			s[sp] = b.consts[in.arg]
+			sp++
+		case opLoad:
+			s[sp] = vars[in.arg]
+			sp++
+		case opNeg:
+			s[sp-1] = -s[sp-1]
+		case opAdd:
+			sp--
+			s[sp-1] += s[sp]
+		case opSub:
+			sp--
+			s[sp-1] -= s[sp]
+		case opMul:
+			sp--
+			s[sp-1] *= s[sp]
+		case opDiv:
+			sp--
+			if s[sp] == 0 {
+				return 0, newEvalError(b.spans[i], in.op, ErrDivisionByZero, s[sp-1], s[sp])
+			}
+			s[sp-1] /= s[sp]
+		case opPow:
+			sp--
+			v, err := pow(s[sp-1], s[sp])
+			if err != nil {
+				return 0, newEvalError(b.spans[i], in.op, err, s[sp-1], s[sp])
+			}
+			s[sp-1] = v
+		case opCall:
+			c := b.calls[in.arg]
+			base := sp - c.argc
+			v, err := c.f.call(s[base:sp])
+			if err != nil {
+				return 0, newEvalError(b.spans[i], c.f, err, boxed(s[base:sp])...)
+			}
+			s[base] = v
+			sp = base + 1
+		}
+	}
+
+	return s[sp-1], nil
+}
+
+// callSite is a call of the function f with argc arguments.
+type callSite struct {
+	f    *function
+	argc int
+}
+
+// emitter emits bytecode in post-order, i.e. operands before their operators. It tracks the number of operands
+// on the stack and deduplicates the constant pool.
+type emitter struct {
+	b bytecode
+	// size is the current number of operands on the stack.
+	size   int
+	consts map[float64]uint32
+}
+
+func newEmitter() *emitter {
+	return &emitter{consts: make(map[float64]uint32)}
+}
+
+func (e *emitter) emit(op opcode, arg uint32, span token.Span, delta int) {
+	e.b.code = append(e.b.code, instr{op: op, arg: arg})
+	e.b.spans = append(e.b.spans, span)
+
+	e.size += delta
+	if e.size > e.b.depth {
+		e.b.depth = e.size
+	}
+}
+
+// constant emits an instruction pushing v.
+func (e *emitter) constant(v float64, span token.Span) {
+	i, ok := e.consts[v]
+	if !ok {
+		i = uint32(len(e.b.consts))
+		e.consts[v] = i
+		e.b.consts = append(e.b.consts, v)
+	}
+	e.emit(opConst, i, span, 1)
+}
+
+// load emits an instruction pushing the variable at index slot.
+func (e *emitter) load(slot int, span token.Span) {
+	e.emit(opLoad, uint32(slot), span, 1)
+}
+
+// neg emits an instruction negating the top of the stack.
+func (e *emitter) neg(span token.Span) {
+	e.emit(opNeg, 0, span, 0)
+}
+
+// call emits an instruction calling f with the argc operands on top of the stack.
+func (e *emitter) call(f *function, argc int, span token.Span) {
+	e.b.calls = append(e.b.calls, callSite{f: f, argc: argc})
+	e.emit(opCall, uint32(len(e.b.calls)-1), span, 1-argc)
+}
+
+// binary emits an instruction applying the binary operator op.
+func (e *emitter) binary(op token.Type, span token.Span) {
+	e.emit(binaryOps[op], 0, span, -1)
+}
@@ -0,0 +1,88 @@
+// Package engine implements the strategies used to evaluate expressions. All engines accept the same
+// language, consume tokens from a syntax.Scanner and report errors of the same types: *syntax.ScanError and
+// *syntax.SyntaxError for invalid input and *EvalError for operations that fail. All of them wrap
+// ErrInvalidInput or the sentinel of the failed operation.
+//
+// Select an engine at run time using Lookup, i.e.
+//
+//	e, err := engine.Lookup("rpn")
+//	if err != nil {
+//		return err
+//	}
+//	result, err := e.Eval(syntax.NewScanner(r), nil)
+package engine
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/halimath/calc/decimal"
+	"github.com/halimath/calc/syntax"
+)
+
+// Engine evaluates expressions scanned by a syntax.Scanner using a particular strategy. Engines differ in
+// performance and in which of several errors contained in the input they report first, but not in the
+// results they calculate.
+type Engine interface {
+	// Name returns the name identifying the engine, such as "ast".
+	Name() string
+
+	// Eval evaluates the expression scanned by s using float64 numbers, calling the functions and using the
+	// constants defined by env, which may be nil. Like Compile and Run, it switches s to ValuesOnly mode (see
+	// syntax.Scanner.ValuesOnly), as the literals of numbers are not needed.
+	Eval(s *syntax.Scanner, env *Env) (float64, error)
+
+	// EvalBig evaluates the expression scanned by s using arbitrary precision floating point numbers with a
+	// mantissa of prec bits. A prec of 0 selects 64 bits.
+	EvalBig(s *syntax.Scanner, prec uint) (*big.Float, error)
+
+	// EvalRat evaluates the expression scanned by s using exact rational numbers.
+	EvalRat(s *syntax.Scanner) (*big.Rat, error)
+
+	// EvalDecimal evaluates the expression scanned by s using decimal fixed-point numbers rounded as defined
+	// by ctx.
+	EvalDecimal(s *syntax.Scanner, ctx decimal.Context) (decimal.Decimal, error)
+
+	// Compile compiles the expression scanned by s, which may contain variables, into a Program evaluating it
+	// using float64 numbers. The program calls the functions and uses the constants defined by env, which may
+	// be nil.
+	Compile(s *syntax.Scanner, env *Env) (*Program, error)
+
+	// Check checks the expression scanned by s without evaluating it. It skips offending input and returns a
+	// syntax.ErrorList containing all errors found, but at most max of them unless max is less than 1.
+	Check(s *syntax.Scanner, max int) error
+
+	// Run runs the script scanned by s using float64 numbers and returns the value of every statement in order.
+	// A script is a sequence of statements separated by semicolons or line breaks, each of which is either an
+	// expression or an assignment name = expr, whose value is the value assigned. Statements may refer to the
+	// variables assigned by preceding ones, which shadow the constants of env of the same name. Run switches s
+	// to script mode (see syntax.Scanner.Script).
+	Run(s *syntax.Scanner, env *Env) ([]float64, error)
+
+	// CheckScript checks the script scanned by s without running it, just like Check does for expressions.
+	CheckScript(s *syntax.Scanner, max int) error
+}
+
+// All returns all engines, starting with the default one.
+func All() []Engine {
+	return []Engine{AST{}, RPN{}}
+}
+
+// Lookup returns the engine with the given name.
+func Lookup(name string) (Engine, error) {
+	for _, e := range All() {
+		if e.Name() == name {
+			return e, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown engine: %q", name)
+}
+
+// bigPrec returns the mantissa size to use for prec, which selects 64 bits if 0, just like big.ParseFloat
+// does.
+func bigPrec(prec uint) uint {
+	if prec == 0 {
+		return 64
+	}
+	return prec
+}
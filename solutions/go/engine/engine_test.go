@@ -0,0 +1,764 @@
+package engine
+
+import (
+	"errors"
+	"io"
+	"math"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/halimath/calc/ast"
+	"github.com/halimath/calc/decimal"
+	"github.com/halimath/calc/rpn"
+	"github.com/halimath/calc/syntax"
+	"github.com/halimath/expect"
+	"github.com/halimath/expect/is"
+)
+
+func TestEngines_Eval(t *testing.T) {
+	type testCase struct {
+		in   string
+		want float64
+		err  error
+	}
+
+	tests := []testCase{
+		{in: "2+3", want: 5},
+		{in: "2+3*(4-5)", want: -1},
+		{in: "10 - 4 - 3", want: 3},
+		{in: "8 / 4 / 2", want: 1},
+		{in: "-(1 + 2) * -(3 - 5)", want: -6},
+		{in: "-+-4", want: 4},
+		{in: "2 ^ 3 ^ 2", want: 512},
+		{in: "-2 ^ 2", want: -4},
+		{in: "2 ^ -1", want: 0.5},
+		{in: "sqrt(3 ^ 2 + 4 ^ 2)", want: 5},
+		{in: "-abs(-2) * 3", want: -6},
+		{in: "min(3, 1, 2) + max(1, 4 - 1)", want: 4},
+		{in: "max(min(2, 5), -1)", want: 2},
+		{in: "floor(2.5) + ceil(2.5) + round(2.5) + round(-2.5)", want: 5},
+		{in: "ln(exp(2)) + log10(1000)", want: 5},
+		{in: "sin(0) + cos(0) + tan(0)", want: 1},
+
+		{in: "", err: syntax.ErrInvalidSyntax},
+		{in: "2+", err: syntax.ErrInvalidSyntax},
+		{in: "2*-", err: syntax.ErrInvalidSyntax},
+		{in: "(1 + 2", err: syntax.ErrInvalidSyntax},
+		{in: "1 + 2)", err: syntax.ErrInvalidSyntax},
+		{in: "abc", err: ErrUndefined},
+		{in: "#", err: syntax.ErrScanFailed},
+		{in: "2.3.", err: syntax.ErrScanFailed},
+		{in: "(", err: ErrInvalidInput},
+		{in: "2/0", err: ErrDivisionByZero},
+		{in: "0 ^ -1", err: ErrDomain},
+		{in: "sqrt(-1)", err: ErrDomain},
+		{in: "ln(0)", err: ErrDomain},
+		{in: "log10(-1)", err: ErrDomain},
+		{in: "exp(1000)", err: ErrDomain},
+		{in: "cbrt(8)", err: ErrUnknownFunction},
+		{in: "sqrt(1, 2)", err: ErrArity},
+		{in: "max()", err: ErrArity},
+		{in: "max(1,)", err: ErrInvalidInput},
+		{in: "1 2", err: syntax.ErrInvalidSyntax},
+		{in: "2 3 +", err: syntax.ErrInvalidSyntax},
+		{in: "2(3)", err: syntax.ErrInvalidSyntax},
+		{in: "max(1 2)", err: syntax.ErrInvalidSyntax},
+		{in: "sqrt(4)(2)", err: syntax.ErrInvalidSyntax},
+		{in: "1 + 2 3", err: syntax.ErrInvalidSyntax},
+		{in: "(1) x", err: syntax.ErrInvalidSyntax},
+		{in: "2 sqrt(4)", err: syntax.ErrInvalidSyntax},
+	}
+
+	for _, e := range All() {
+		for _, test := range tests {
+			got, err := e.Eval(syntax.NewScanner(strings.NewReader(test.in)), nil)
+
+			expect.WithMessage(t, "%s: in: %q", e.Name(), test.in).That(
+				is.Error(err, test.err),
+				is.EqualTo(got, test.want),
+			)
+		}
+	}
+}
+
+func TestEngines_long(t *testing.T) {
+	// Limit the goroutine stacks to make sure no engine recurses per token or per parenthesis. Exceeding the
+	// limit aborts the test binary.
+	defer debug.SetMaxStack(debug.SetMaxStack(1 << 20))
+
+	const n = 100_000
+
+	type testCase struct {
+		label string
+		in    string
+		want  float64
+	}
+
+	tests := []testCase{
+		{label: "flat sum", in: "1" + strings.Repeat(" + 1", n-1), want: n},
+		{label: "flat mixed", in: "1" + strings.Repeat(" * 2 / 2", n), want: 1},
+		{label: "nested", in: strings.Repeat("(1 + ", n) + "0" + strings.Repeat(")", n), want: n},
+		{label: "parenthesis", in: strings.Repeat("(", n) + "1" + strings.Repeat(")", n), want: 1},
+		{label: "signs", in: strings.Repeat("- ", n) + "1", want: 1},
+	}
+
+	for _, e := range All() {
+		for _, test := range tests {
+			got, err := e.Eval(syntax.NewScanner(strings.NewReader(test.in)), nil)
+
+			expect.WithMessage(t, "%s: %s", e.Name(), test.label).That(
+				is.NoError(err),
+				is.EqualTo(got, test.want),
+			)
+		}
+	}
+}
+
+func TestEngines_numberTypes(t *testing.T) {
+	const in = "0.1 + 0.2 * (3 - 1) / 4"
+
+	for _, e := range All() {
+		f, err := e.EvalBig(syntax.NewScanner(strings.NewReader(in)), 256)
+		expect.WithMessage(t, "%s", e.Name()).That(
+			is.NoError(err),
+			is.EqualTo(f.Text('f', 3), "0.200"),
+		)
+
+		r, err := e.EvalRat(syntax.NewScanner(strings.NewReader(in)))
+		expect.WithMessage(t, "%s", e.Name()).That(
+			is.NoError(err),
+			is.EqualTo(r.RatString(), "1/5"),
+		)
+
+		d, err := e.EvalDecimal(syntax.NewScanner(strings.NewReader(in)), decimal.Context{Scale: 2})
+		expect.WithMessage(t, "%s", e.Name()).That(
+			is.NoError(err),
+			is.EqualTo(d.String(), "0.20"),
+		)
+
+		_, err = e.EvalRat(syntax.NewScanner(strings.NewReader("4 ^ 0.5")))
+		expect.WithMessage(t, "%s", e.Name()).That(is.Error(err, errors.ErrUnsupported))
+
+		const calls = "abs(-0.1) + min(0.3, 0.2, 0.4) * max(-1, 2)"
+
+		f, err = e.EvalBig(syntax.NewScanner(strings.NewReader(calls)), 256)
+		expect.WithMessage(t, "%s", e.Name()).That(
+			is.NoError(err),
+			is.EqualTo(f.Text('f', 3), "0.500"),
+		)
+
+		r, err = e.EvalRat(syntax.NewScanner(strings.NewReader(calls)))
+		expect.WithMessage(t, "%s", e.Name()).That(
+			is.NoError(err),
+			is.EqualTo(r.RatString(), "1/2"),
+		)
+
+		d, err = e.EvalDecimal(syntax.NewScanner(strings.NewReader(calls)), decimal.Context{Scale: 2})
+		expect.WithMessage(t, "%s", e.Name()).That(
+			is.NoError(err),
+			is.EqualTo(d.String(), "0.50"),
+		)
+
+		_, err = e.EvalRat(syntax.NewScanner(strings.NewReader("sqrt(4)")))
+		expect.WithMessage(t, "%s", e.Name()).That(is.Error(err, errors.ErrUnsupported))
+	}
+}
+
+func TestEngines_errorTypes(t *testing.T) {
+	for _, e := range All() {
+		_, err := e.Eval(syntax.NewScanner(strings.NewReader("1 + #")), nil)
+		var scanErr *syntax.ScanError
+		expect.WithMessage(t, "%s", e.Name()).That(
+			is.EqualTo(errors.As(err, &scanErr), true),
+			is.EqualTo(scanErr.Kind, syntax.InvalidRune),
+		)
+
+		_, err = e.Eval(syntax.NewScanner(strings.NewReader("2 * (3 + 4")), nil)
+		var syntaxErr *syntax.SyntaxError
+		expect.WithMessage(t, "%s", e.Name()).That(
+			is.EqualTo(errors.As(err, &syntaxErr), true),
+			is.EqualTo(syntaxErr.Kind, syntax.UnclosedParen),
+			is.EqualTo(syntaxErr.Opening.Start.Offset, 4),
+		)
+
+		_, err = e.Eval(syntax.NewScanner(strings.NewReader("1 + 6 / (2 - 2)")), nil)
+		var evalErr *EvalError
+		expect.WithMessage(t, "%s", e.Name()).That(
+			is.EqualTo(errors.As(err, &evalErr), true),
+			is.EqualTo(evalErr.Kind, DivisionByZero),
+			is.EqualTo(evalErr.Span.Start.Offset, 6),
+			is.DeepEqualTo(evalErr.Operands, []any{6.0, 0.0}),
+		)
+
+		_, err = e.Eval(syntax.NewScanner(strings.NewReader("1 + sqrt(1 - 2)")), nil)
+		expect.WithMessage(t, "%s", e.Name()).That(
+			is.EqualTo(errors.As(err, &evalErr), true),
+			is.EqualTo(evalErr.Kind, Domain),
+			is.EqualTo(evalErr.Op, "sqrt"),
+			is.EqualTo(evalErr.Span.Start.Offset, 4),
+			is.DeepEqualTo(evalErr.Operands, []any{-1.0}),
+			is.EqualTo(evalErr.Error(), "1:5: domain error: sqrt(-1)"),
+		)
+
+		_, err = e.Eval(syntax.NewScanner(strings.NewReader("2 * min()")), nil)
+		var callErr *CallError
+		expect.WithMessage(t, "%s", e.Name()).That(
+			is.EqualTo(errors.As(err, &callErr), true),
+			is.EqualTo(callErr.Name, "min"),
+			is.EqualTo(callErr.Error(), "1:5: wrong number of arguments: min expects at least 1 argument but got 0"),
+		)
+
+		_, err = e.Eval(syntax.NewScanner(strings.NewReader("2 * cbrt(8)")), nil)
+		expect.WithMessage(t, "%s", e.Name()).That(
+			is.EqualTo(errors.As(err, &callErr), true),
+			is.EqualTo(callErr.Error(), "1:5: unknown function: cbrt"),
+		)
+	}
+}
+
+func TestRPN_constantMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping evaluation of 100 MB in short mode")
+	}
+
+	r := &flatReader{size: 100 << 20}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	got, err := RPN{}.Eval(syntax.NewScanner(r), nil)
+	runtime.ReadMemStats(&after)
+
+	expect.That(t,
+		is.NoError(err),
+		is.EqualTo(got, 12.5*float64(r.terms)),
+		is.EqualTo(after.TotalAlloc-before.TotalAlloc < 1<<20, true),
+	)
+}
+
+// flatReader generates a flat sum of terms 12.5 of about size bytes without keeping it in memory. terms is
+// the number of terms generated so far.
+type flatReader struct {
+	size, terms int
+	// off is the offset into the term being generated.
+	off int
+}
+
+const flatTerm = "12.5 + "
+
+func (r *flatReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if r.off == 0 {
+			if r.size < len(flatTerm) {
+				break
+			}
+			r.size -= len(flatTerm)
+			r.terms++
+		}
+
+		term := flatTerm
+		if r.size < len(flatTerm) {
+			// Omit the operator following the last term.
+			term = flatTerm[:4]
+		}
+		c := copy(p[n:], term[r.off:])
+		n += c
+		r.off = (r.off + c) % len(term)
+	}
+
+	if n == 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+func TestRPN_missingOperand(t *testing.T) {
+	_, err := RPN{}.Eval(syntax.NewScanner(strings.NewReader("3 *")), nil)
+
+	var got *syntax.SyntaxError
+	expect.That(t,
+		is.Error(err, ErrInvalidInput),
+		is.EqualTo(errors.As(err, &got), true),
+		is.EqualTo(got.Kind, syntax.MissingOperand),
+		is.EqualTo(got.Span.Start.Offset, 2),
+		is.EqualTo(got.Found, "*"),
+	)
+}
+
+func TestEngines_Check(t *testing.T) {
+	type testCase struct {
+		in   string
+		max  int
+		want map[string][]string
+	}
+
+	tests := []testCase{
+		{in: "1 + 2 * (3 - 4)"},
+		{in: "", want: map[string][]string{
+			"ast": {"1:1: invalid syntax: unexpected end of input"},
+			"rpn": {"1:1: invalid syntax: missing operand"},
+		}},
+		{in: "1 + * 2 )\n* #", want: map[string][]string{
+			"ast": {
+				"1:5: invalid syntax: unexpected \"*\"",
+				"1:9: invalid syntax: unexpected \")\"",
+				"2:3: scan failed: invalid input rune: #",
+			},
+			"rpn": {
+				"1:3: invalid syntax: missing operand for \"+\"",
+				"1:9: invalid syntax: unexpected \")\"",
+				"2:3: scan failed: invalid input rune: #",
+			},
+		}},
+		{in: "1 + * 2 )\n* #", max: 2, want: map[string][]string{
+			"ast": {
+				"1:5: invalid syntax: unexpected \"*\"",
+				"1:9: invalid syntax: unexpected \")\"",
+			},
+			"rpn": {
+				"1:3: invalid syntax: missing operand for \"+\"",
+				"1:9: invalid syntax: unexpected \")\"",
+			},
+		}},
+		{in: "2(3 + #) + 4 5 * max(1 2)", want: map[string][]string{
+			"ast": {
+				"1:2: invalid syntax: unexpected \"(\"",
+				"1:7: scan failed: invalid input rune: #",
+				"1:14: invalid syntax: unexpected \"5\"",
+				"1:24: invalid syntax: unexpected \"2\"",
+			},
+			"rpn": {
+				"1:2: invalid syntax: unexpected \"(\"",
+				"1:7: scan failed: invalid input rune: #",
+				"1:14: invalid syntax: unexpected \"5\"",
+				"1:24: invalid syntax: unexpected \"2\"",
+			},
+		}},
+	}
+
+	for _, e := range All() {
+		for _, test := range tests {
+			err := e.Check(syntax.NewScanner(strings.NewReader(test.in)), test.max)
+
+			var got []string
+			var errs syntax.ErrorList
+			if errors.As(err, &errs) {
+				for _, e := range errs {
+					got = append(got, e.Error())
+				}
+			}
+
+			expect.WithMessage(t, "%s: in: %q", e.Name(), test.in).That(
+				is.DeepEqualTo(got, test.want[e.Name()]),
+			)
+		}
+	}
+}
+
+func TestEngines_Run(t *testing.T) {
+	env := NewEnv()
+	env.Const("rate", 0.19)
+
+	type testCase struct {
+		in   string
+		want []float64
+		err  error
+	}
+
+	tests := []testCase{
+		{in: ""},
+		{in: "\n;\n"},
+		{in: "1 + 2", want: []float64{3}},
+		{in: "net = 1200 * 12; tax = net * 0.19; net - tax", want: []float64{14400, 2736, 11664}},
+		{in: "x = 2\n\nx = x *\n  (x +\n 1)\nmax(x, 3)\n", want: []float64{2, 6, 6}},
+		{in: "rate = rate * 2; rate", want: []float64{0.38, 0.38}},
+		{in: "x = 1; y", err: ErrUndefined},
+		{in: "y = y + 1", err: ErrUndefined},
+		{in: "x = 1; x / 0", err: ErrDivisionByZero},
+		{in: "x = ; 1", err: ErrInvalidInput},
+		{in: "x = 1 = 2", err: ErrInvalidInput},
+		{in: "1\n+ 2", want: []float64{1, 2}},
+	}
+
+	for _, e := range All() {
+		for _, test := range tests {
+			got, err := e.Run(syntax.NewScanner(strings.NewReader(test.in)), env)
+
+			expect.WithMessage(t, "%s: in: %q", e.Name(), test.in).That(
+				is.Error(err, test.err),
+				is.DeepEqualTo(got, test.want),
+			)
+		}
+
+		got, err := e.Eval(syntax.NewScanner(strings.NewReader("rate")), env)
+		expect.WithMessage(t, "%s: scope", e.Name()).That(
+			is.NoError(err),
+			is.EqualTo(got, 0.19),
+		)
+	}
+}
+
+func TestEngines_CheckScript(t *testing.T) {
+	// A line break following an operator continues the statement.
+	want := []string{
+		"2:3: invalid syntax: unexpected \"=\"",
+		"3:3: invalid syntax: expected ) but got \";\" (unclosed ( at 3:1)",
+		"3:6: invalid syntax: unexpected \")\"",
+	}
+
+	for _, e := range All() {
+		err := e.CheckScript(syntax.NewScanner(strings.NewReader("x = 1 +\ny = * 2\n(x; 1)\nz = 3")), 0)
+
+		var got []string
+		var errs syntax.ErrorList
+		if errors.As(err, &errs) {
+			for _, e := range errs {
+				got = append(got, e.Error())
+			}
+		}
+
+		expect.WithMessage(t, "%s", e.Name()).That(
+			is.DeepEqualTo(got, want),
+		)
+	}
+}
+
+func TestEngines_Compile(t *testing.T) {
+	for _, e := range All() {
+		p, err := e.Compile(syntax.NewScanner(strings.NewReader("(a - b) ^ 2 / b + a")), nil)
+		expect.WithMessage(t, "%s", e.Name()).That(
+			is.NoError(err),
+			is.DeepEqualTo(p.Vars(), []string{"a", "b"}),
+		)
+
+		got, err := p.Eval(map[string]float64{"a": 5, "b": 2})
+		expect.WithMessage(t, "%s", e.Name()).That(
+			is.NoError(err),
+			is.EqualTo(got, 9.5),
+		)
+
+		_, err = p.Eval(map[string]float64{"a": 5, "b": 0})
+		expect.WithMessage(t, "%s", e.Name()).That(is.Error(err, ErrDivisionByZero))
+
+		_, err = p.Eval(map[string]float64{"a": 5})
+		var undefErr *UndefinedError
+		expect.WithMessage(t, "%s", e.Name()).That(
+			is.Error(err, ErrUndefined),
+			is.EqualTo(errors.As(err, &undefErr), true),
+			is.EqualTo(undefErr.Name, "b"),
+			is.EqualTo(undefErr.Span.Start.Offset, 5),
+		)
+
+		// Names not referred to by the program are ignored.
+		b, err := p.Bind("c", "b", "a")
+		expect.WithMessage(t, "%s", e.Name()).That(is.NoError(err))
+
+		for _, values := range [][]float64{{0, 2, 5}, {1, 1, 3}} {
+			got, err := b.Eval(values)
+			want, _ := p.Eval(map[string]float64{"a": values[2], "b": values[1]})
+			expect.WithMessage(t, "%s: values: %v", e.Name(), values).That(
+				is.NoError(err),
+				is.EqualTo(got, want),
+			)
+		}
+
+		_, err = b.Eval([]float64{1, 2})
+		expect.WithMessage(t, "%s", e.Name()).That(is.Error(err, ErrInvalidInput))
+
+		_, err = p.Bind("a")
+		expect.WithMessage(t, "%s", e.Name()).That(is.Error(err, ErrUndefined))
+
+		_, err = e.Compile(syntax.NewScanner(strings.NewReader("a +")), nil)
+		expect.WithMessage(t, "%s", e.Name()).That(is.Error(err, syntax.ErrInvalidSyntax))
+	}
+}
+
+func TestEnv(t *testing.T) {
+	errNegative := errors.New("negative amount")
+
+	env := NewEnv()
+	env.Const("pi", math.Pi)
+	env.Const("taxRate", 0.25)
+	env.Func("vat", 1, func(args []float64) (float64, error) {
+		if args[0] < 0 {
+			return 0, errNegative
+		}
+		return args[0] * 0.25, nil
+	})
+	env.Variadic("sum", 0, func(args []float64) (float64, error) {
+		var sum float64
+		for _, arg := range args {
+			sum += arg
+		}
+		return sum, nil
+	})
+	env.Func("inv", 1, func(args []float64) (float64, error) { return 1 / args[0], nil })
+	// Functions of the Env take precedence over builtins.
+	env.Func("abs", 2, func(args []float64) (float64, error) { return math.Hypot(args[0], args[1]), nil })
+
+	type testCase struct {
+		in   string
+		want float64
+		err  error
+	}
+
+	tests := []testCase{
+		{in: "vat(100) + taxRate", want: 25.25},
+		{in: "sum() + sum(1) + sum(1, 2, 3)", want: 7},
+		{in: "round(cos(pi))", want: -1},
+		{in: "abs(3, 4)", want: 5},
+		{in: "vat(-1)", err: errNegative},
+		{in: "inv(0)", err: ErrDomain},
+		{in: "vat()", err: ErrArity},
+		{in: "abs(-1)", err: ErrArity},
+		{in: "gross(1)", err: ErrUnknownFunction},
+		{in: "tau", err: ErrUndefined},
+	}
+
+	for _, e := range All() {
+		for _, test := range tests {
+			got, err := e.Eval(syntax.NewScanner(strings.NewReader(test.in)), env)
+
+			expect.WithMessage(t, "%s: in: %q", e.Name(), test.in).That(
+				is.Error(err, test.err),
+				is.EqualTo(got, test.want),
+			)
+
+			p, err := e.Compile(syntax.NewScanner(strings.NewReader(test.in)), env)
+			if err == nil {
+				got, err = p.Eval(nil)
+			}
+
+			expect.WithMessage(t, "%s: compiled: in: %q", e.Name(), test.in).That(
+				is.Error(err, test.err),
+				is.EqualTo(got, test.want),
+			)
+		}
+
+		_, err := e.Eval(syntax.NewScanner(strings.NewReader("1 +\n  vat(1, 2)")), env)
+		var callErr *CallError
+		expect.WithMessage(t, "%s", e.Name()).That(
+			is.EqualTo(errors.As(err, &callErr), true),
+			is.EqualTo(callErr.Error(), "2:3: wrong number of arguments: vat expects 1 argument but got 2"),
+		)
+
+		p, err := e.Compile(syntax.NewScanner(strings.NewReader("pi * r ^ 2")), env)
+		expect.WithMessage(t, "%s", e.Name()).That(
+			is.NoError(err),
+			is.DeepEqualTo(p.Vars(), []string{"r"}),
+		)
+	}
+}
+
+func TestEnv_invalidName(t *testing.T) {
+	for _, name := range []string{"", "2x", "a-b", "x y"} {
+		var recovered any
+		func() {
+			defer func() { recovered = recover() }()
+			NewEnv().Const(name, 1)
+		}()
+
+		expect.WithMessage(t, "name: %q", name).That(is.EqualTo(recovered != nil, true))
+	}
+}
+
+func TestEnv_nonFiniteConst(t *testing.T) {
+	for _, value := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		var recovered any
+		func() {
+			defer func() { recovered = recover() }()
+			NewEnv().Const("x", value)
+		}()
+
+		expect.WithMessage(t, "value: %v", value).That(is.EqualTo(recovered != nil, true))
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for _, want := range All() {
+		got, err := Lookup(want.Name())
+		expect.That(t,
+			is.NoError(err),
+			is.EqualTo(got, want),
+		)
+	}
+
+	_, err := Lookup("abacus")
+	expect.That(t, is.EqualTo(err.Error(), "unknown engine: \"abacus\""))
+}
+
+func TestEvalTree(t *testing.T) {
+	root, err := syntax.NewParser(syntax.NewScanner(strings.NewReader("2 * (3 + 4)"))).Expr()
+	expect.That(t, is.NoError(err))
+
+	got, err := EvalTree(root)
+	expect.That(t,
+		is.NoError(err),
+		is.EqualTo(got, 14.0),
+	)
+
+	_, err = EvalTree(ast.Operator{L: ast.Number{Value: 1, Literal: "1"}, R: ast.Number{Value: 0, Literal: "0"}, Op: ast.Div})
+	expect.That(t, is.Error(err, ErrDivisionByZero))
+}
+
+func TestEvalRPN(t *testing.T) {
+	got, err := EvalRPN(rpn.New(syntax.NewScanner(strings.NewReader("2 * (3 + 4)"))))
+	expect.That(t,
+		is.NoError(err),
+		is.EqualTo(got, 14.0),
+	)
+
+	// Input already in RPN may be read from a scanner directly.
+	got, err = EvalRPN(syntax.NewScanner(strings.NewReader("2 3 4 + *")))
+	expect.That(t,
+		is.NoError(err),
+		is.EqualTo(got, 14.0),
+	)
+
+	_, err = EvalRPN(syntax.NewScanner(strings.NewReader("1 0 /")))
+	expect.That(t, is.Error(err, ErrDivisionByZero))
+
+	// Operands left without an operator are rejected.
+	_, err = EvalRPN(syntax.NewScanner(strings.NewReader("1 2 3 +")))
+	expect.That(t, is.Error(err, syntax.ErrInvalidSyntax))
+	_, err = CompileRPN(syntax.NewScanner(strings.NewReader("1 2 3 +")), nil)
+	expect.That(t, is.Error(err, syntax.ErrInvalidSyntax))
+}
+
+func TestEvalParallel(t *testing.T) {
+	env := NewEnv()
+	env.Const("x", 0.1)
+	env.Func("half", 1, func(args []float64) (float64, error) { return args[0] / 2, nil })
+
+	in := parallelInput(3 * segmentSize)
+
+	type testCase struct {
+		label string
+		in    string
+	}
+
+	tests := []testCase{
+		{label: "valid", in: in},
+		{label: "short", in: "1 - 2 * 3"},
+		{label: "unclosed parenthesis", in: in + " + (1"},
+		{label: "missing operand", in: in + " - "},
+		{label: "division by zero", in: "1 / 0 + " + in},
+		{label: "undefined", in: in + " - y"},
+		{label: "semicolon", in: in + "; 1"},
+		{label: "missing operator", in: strings.Repeat("1 + ", 200_000) + "1 2 + 1"},
+		{label: "call of a number", in: in + " - 2(3) + 1"},
+		{label: "missing comma", in: "max(1 2) * 3 + " + in},
+		{label: "call of a call", in: in + " + sqrt(4)(2)"},
+		{label: "operand after parenthesis", in: in + " - (1) x"},
+	}
+
+	for _, test := range tests {
+		for _, e := range All() {
+			want, wantErr := e.Eval(syntax.NewScanner(strings.NewReader(test.in)), env)
+			got, err := EvalParallel(e, []byte(test.in), env, 4)
+
+			expect.WithMessage(t, "%s: %s", e.Name(), test.label).That(
+				is.EqualTo(math.Float64bits(got), math.Float64bits(want)),
+				is.DeepEqualTo(err, wantErr),
+			)
+		}
+	}
+}
+
+// parallelInput generates an expression of at least size bytes consisting of terms of various kinds.
+func parallelInput(size int) string {
+	terms := []string{"12.75", "(0.3 - 1.7) / 3", "-2 ^ 2", "half(5.5) * x", "max(1, 2.5) ^ 1.5", "- -4.125"}
+	separators := []string{" + ", " - ", "\n- ", " +\n"}
+
+	var b strings.Builder
+	b.WriteString("0.1")
+	for i := 0; b.Len() < size; i++ {
+		b.WriteString(separators[i%len(separators)])
+		b.WriteString(terms[i%len(terms)])
+	}
+	return b.String()
+}
+
+func TestCompileTree(t *testing.T) {
+	root, err := syntax.NewParser(syntax.NewScanner(strings.NewReader("-x * (2 + x) / 2"))).Expr()
+	expect.That(t, is.NoError(err))
+
+	p, err := CompileTree(root, nil)
+	expect.That(t,
+		is.NoError(err),
+		is.EqualTo(p.code.String(), "load 0\nneg\nconst 2\nload 0\n+\n*\nconst 2\n/\n"),
+		is.SliceOfLen(p.code.consts, 1),
+		is.EqualTo(p.code.depth, 3),
+	)
+}
+
+func TestCompileRPN(t *testing.T) {
+	p, err := CompileRPN(syntax.NewScanner(strings.NewReader("2 3 4 + * x ^")), nil)
+	expect.That(t,
+		is.NoError(err),
+		is.EqualTo(p.code.String(), "const 2\nconst 3\nconst 4\n+\n*\nload 0\n^\n"),
+		is.EqualTo(p.code.depth, 3),
+	)
+
+	p, err = CompileRPN(rpn.New(syntax.NewScanner(strings.NewReader("max(x, 1, sqrt(y))"))), nil)
+	expect.That(t,
+		is.NoError(err),
+		is.EqualTo(p.code.String(), "load 0\nconst 1\nload 1\ncall sqrt/1\ncall max/3\n"),
+		is.EqualTo(p.code.depth, 3),
+	)
+
+	_, err = CompileRPN(rpn.New(syntax.NewScanner(strings.NewReader("x + sqrt()"))), nil)
+	expect.That(t, is.Error(err, ErrArity))
+
+	_, err = CompileRPN(syntax.NewScanner(strings.NewReader("2 +")), nil)
+	expect.That(t, is.Error(err, syntax.ErrInvalidSyntax))
+}
+
+func TestProgram_run(t *testing.T) {
+	// Nest deeper than stackSize to make the program allocate its operand stack.
+	const n = 2 * stackSize
+	in := strings.Repeat("(x + ", n) + "0" + strings.Repeat(")", n)
+
+	for _, e := range All() {
+		p, err := e.Compile(syntax.NewScanner(strings.NewReader(in)), nil)
+		expect.WithMessage(t, "%s", e.Name()).That(is.NoError(err))
+
+		got, err := p.Eval(map[string]float64{"x": 2})
+		expect.WithMessage(t, "%s", e.Name()).That(
+			is.NoError(err),
+			is.EqualTo(got, 2.0*n),
+		)
+
+		p, err = e.Compile(syntax.NewScanner(strings.NewReader("1 + x ^ (1 / y)")), nil)
+		expect.WithMessage(t, "%s", e.Name()).That(is.NoError(err))
+
+		_, err = p.Eval(map[string]float64{"x": -8, "y": 2})
+		var evalErr *EvalError
+		expect.WithMessage(t, "%s", e.Name()).That(
+			is.EqualTo(errors.As(err, &evalErr), true),
+			is.EqualTo(evalErr.Kind, Domain),
+			is.EqualTo(evalErr.Op, "^"),
+			is.EqualTo(evalErr.Span.Start.Offset, 6),
+			is.DeepEqualTo(evalErr.Operands, []any{-8.0, 0.5}),
+		)
+
+		p, err = e.Compile(syntax.NewScanner(strings.NewReader("max(x, 2) + ln(x - 1)")), nil)
+		expect.WithMessage(t, "%s", e.Name()).That(is.NoError(err))
+
+		got, err = p.Eval(map[string]float64{"x": 3})
+		expect.WithMessage(t, "%s", e.Name()).That(
+			is.NoError(err),
+			is.EqualTo(got, 3+math.Ln2),
+		)
+
+		_, err = p.Eval(map[string]float64{"x": 1})
+		expect.WithMessage(t, "%s", e.Name()).That(
+			is.EqualTo(errors.As(err, &evalErr), true),
+			is.EqualTo(evalErr.Op, "ln"),
+			is.EqualTo(evalErr.Span.Start.Offset, 12),
+			is.DeepEqualTo(evalErr.Operands, []any{0.0}),
+		)
+	}
+}
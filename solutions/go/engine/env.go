@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"strconv"
+	"unicode"
+
+	"github.com/halimath/calc/token"
+)
+
+// Env defines functions and constants available to expressions in addition to the builtin functions, such as
+//
+//	env := engine.NewEnv()
+//	env.Const("pi", math.Pi)
+//	env.Func("vat", 1, func(args []float64) (float64, error) { return args[0] * 0.19, nil })
+//
+// Functions defined by an Env take precedence over builtins of the same name. Constants take precedence over
+// variables, so a Program compiled using an Env does not refer to any variable named like a constant. An Env
+// must not be modified while it is used to evaluate or compile expressions. A nil *Env defines nothing but the
+// builtins.
+type Env struct {
+	funcs  map[string]*function
+	consts map[string]float64
+}
+
+// NewEnv creates an empty Env.
+func NewEnv() *Env {
+	return &Env{
+		funcs:  make(map[string]*function),
+		consts: make(map[string]float64),
+	}
+}
+
+// Func defines the function name accepting exactly arity arguments. fn is called with arity arguments only.
+// Errors returned by fn are reported as the Err of an *EvalError, just like a NaN or infinite result, which is
+// reported as ErrDomain. Func panics if name is not an identifier or arity is negative.
+func (e *Env) Func(name string, arity int, fn func(args []float64) (float64, error)) {
+	e.define(name, arity, false, fn)
+}
+
+// Variadic defines the function name accepting min or more arguments. See Func for details.
+func (e *Env) Variadic(name string, min int, fn func(args []float64) (float64, error)) {
+	e.define(name, min, true, fn)
+}
+
+func (e *Env) define(name string, arity int, variadic bool, fn func(args []float64) (float64, error)) {
+	if !isIdent(name) {
+		panic(fmt.Sprintf("engine: invalid function name: %q", name))
+	}
+	if arity < 0 {
+		panic(fmt.Sprintf("engine: negative arity for function %s: %d", name, arity))
+	}
+
+	e.funcs[name] = &function{name: name, arity: arity, variadic: variadic, fn: fn}
+}
+
+// Const defines the constant name. Const panics if name is not an identifier or value is NaN or infinite,
+// which no expression evaluates to.
+func (e *Env) Const(name string, value float64) {
+	if !isIdent(name) {
+		panic(fmt.Sprintf("engine: invalid constant name: %q", name))
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		panic(fmt.Sprintf("engine: non-finite value for constant %s: %v", name, value))
+	}
+
+	e.consts[name] = value
+}
+
+// scope returns the Env a script is run in: it defines the functions of e and a copy of its constants, which
+// the variables assigned by the script are added to.
+func (e *Env) scope() *Env {
+	s := Env{consts: make(map[string]float64)}
+	if e != nil {
+		s.funcs = e.funcs
+		maps.Copy(s.consts, e.consts)
+	}
+	return &s
+}
+
+// function returns the function called by name with n arguments. It reports a *CallError located at span if
+// there is no such function or it does not accept n arguments.
+func (e *Env) function(name string, n int, span token.Span) (*function, error) {
+	var f *function
+	if e != nil {
+		f = e.funcs[name]
+	}
+	if f == nil {
+		f = builtins[name]
+	}
+
+	if f == nil {
+		return nil, &CallError{Name: name, Span: span, Err: ErrUnknownFunction}
+	}
+	if err := f.checkArity(n); err != nil {
+		return nil, &CallError{Name: name, Span: span, Err: err}
+	}
+	return f, nil
+}
+
+// constant returns the value of the constant name.
+func (e *Env) constant(name string) (float64, bool) {
+	if e == nil {
+		return 0, false
+	}
+	v, ok := e.consts[name]
+	return v, ok
+}
+
+// constToken returns a Number token holding the value of the constant name located at offset, which the
+// arithmetic of any number type is able to convert.
+func constToken(name string, v float64, offset int) token.Token {
+	return token.Token{Type: token.Number, Value: v, Literal: strconv.FormatFloat(v, 'g', -1, 64), Offset: offset}
+}
+
+// isIdent returns whether name is an identifier as read by syntax.Scanner.
+func isIdent(name string) bool {
+	for i, r := range name {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return name != ""
+}
@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/halimath/calc/token"
+)
+
+var (
+	// ErrInvalidInput is wrapped by all errors caused by input that is not a valid expression.
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrDivisionByZero is wrapped by errors reporting a division by zero.
+	ErrDivisionByZero = errors.New("division by zero")
+	// ErrDomain is wrapped by errors reporting an operation whose result is not a finite real number.
+	ErrDomain = errors.New("domain error")
+	// ErrUndefined is wrapped by errors reporting a reference to an undefined variable.
+	ErrUndefined = errors.New("undefined variable")
+	// ErrUnknownFunction is wrapped by errors reporting a call of a function that does not exist.
+	ErrUnknownFunction = errors.New("unknown function")
+	// ErrArity is wrapped by errors reporting a call with the wrong number of arguments.
+	ErrArity = errors.New("wrong number of arguments")
+)
+
+// EvalErrorKind classifies EvalErrors.
+type EvalErrorKind int
+
+const (
+	// DivisionByZero reports a division with a right operand of 0. The error wraps ErrDivisionByZero.
+	DivisionByZero EvalErrorKind = iota + 1
+	// Domain reports an operation whose result is not a finite real number. The error wraps ErrDomain.
+	Domain
+	// Unsupported reports an operation not supported by the number type used for evaluation. The error wraps
+	// errors.ErrUnsupported.
+	Unsupported
+)
+
+func (k EvalErrorKind) String() string {
+	switch k {
+	case DivisionByZero:
+		return "division by zero"
+	case Domain:
+		return "domain error"
+	case Unsupported:
+		return "unsupported"
+	default:
+		return fmt.Sprintf("EvalErrorKind(%d)", int(k))
+	}
+}
+
+// EvalError describes an operation that failed during evaluation.
+type EvalError struct {
+	Kind EvalErrorKind
+	// Span is the location of the operator.
+	Span token.Span
+	// Op is the operator, such as "/".
+	Op string
+	// Operands contains the values the operator has been applied to. Their type is the number type used for
+	// evaluation, i.e. float64 for Eval or *big.Rat for EvalRat.
+	Operands []any
+	// Err is the error reported by the operation.
+	Err error
+}
+
+func newEvalError(span token.Span, op fmt.Stringer, err error, operands ...any) *EvalError {
+	e := EvalError{
+		Span:     span,
+		Op:       op.String(),
+		Operands: operands,
+		Err:      err,
+	}
+
+	switch {
+	case errors.Is(err, ErrDivisionByZero):
+		e.Kind = DivisionByZero
+	case errors.Is(err, ErrDomain):
+		e.Kind = Domain
+	case errors.Is(err, errors.ErrUnsupported):
+		e.Kind = Unsupported
+	}
+
+	return &e
+}
+
+// Message returns the error's message without the position prefix.
+func (e *EvalError) Message() string { return e.Err.Error() }
+
+func (e *EvalError) Error() string { return fmt.Sprintf("%s: %s", e.Span, e.Message()) }
+
+func (e *EvalError) Unwrap() error { return e.Err }
+
+// UndefinedError describes a reference to a variable without a value.
+type UndefinedError struct {
+	Name string
+	// Span is the location of the reference.
+	Span token.Span
+}
+
+// Message returns the error's message without the position prefix.
+func (e *UndefinedError) Message() string { return fmt.Sprintf("%v: %s", ErrUndefined, e.Name) }
+
+func (e *UndefinedError) Error() string { return fmt.Sprintf("%s: %s", e.Span, e.Message()) }
+
+func (e *UndefinedError) Unwrap() error { return ErrUndefined }
+
+// CallError describes a call of an unknown function or a call with the wrong number of arguments.
+type CallError struct {
+	// Name is the name of the function.
+	Name string
+	// Span is the location of the function name.
+	Span token.Span
+	// Err is ErrUnknownFunction or an error wrapping ErrArity.
+	Err error
+}
+
+// Message returns the error's message without the position prefix.
+func (e *CallError) Message() string {
+	if e.Err == ErrUnknownFunction {
+		return fmt.Sprintf("%v: %s", e.Err, e.Name)
+	}
+	return e.Err.Error()
+}
+
+func (e *CallError) Error() string { return fmt.Sprintf("%s: %s", e.Span, e.Message()) }
+
+func (e *CallError) Unwrap() error { return e.Err }
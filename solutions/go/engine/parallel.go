@@ -0,0 +1,192 @@
+package engine
+
+import (
+	"bytes"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/halimath/calc/rpn"
+	"github.com/halimath/calc/syntax"
+	"github.com/halimath/calc/token"
+)
+
+// segmentSize is the minimum size of the segments of input EvalParallel evaluates concurrently.
+const segmentSize = 256 * 1024
+
+// EvalParallel evaluates the expression src using float64 numbers on up to workers goroutines, or
+// runtime.GOMAXPROCS(0) if workers is less than 1. It splits src into segments at the operators + and -
+// outside of any parenthesis, which separate the terms of the expression. The terms of every segment are
+// evaluated concurrently and the results are added up from left to right once all are known, so the result
+// is exactly the one e.Eval calculates. The functions of env, which may be nil, must be safe for concurrent
+// use.
+//
+// The terms are converted to RPN whatever engine e is, which accepts exactly the expressions every Engine
+// does: a term such as 2(3) or 1 2, which misses an operator between two operands, is rejected rather than
+// split into valid terms, and so is a term leaving more than one value. Input too short to be worth
+// splitting is evaluated by e as a whole, and so is input any segment of which fails to evaluate. Errors
+// are therefore reported exactly like e.Eval does.
+func EvalParallel(e Engine, src []byte, env *Env, workers int) (float64, error) {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	var segs []segment
+	if workers > 1 && len(src) >= 2*segmentSize {
+		segs = split(src)
+	}
+	if len(segs) < 2 {
+		return e.Eval(syntax.NewScanner(bytes.NewReader(src)), env)
+	}
+
+	var (
+		wg     sync.WaitGroup
+		next   atomic.Int64
+		failed atomic.Bool
+	)
+	for range min(workers, len(segs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var buf []byte
+			for !failed.Load() {
+				i := int(next.Add(1) - 1)
+				if i >= len(segs) {
+					return
+				}
+				if !segs[i].eval(&buf, env) {
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if failed.Load() {
+		return e.Eval(syntax.NewScanner(bytes.NewReader(src)), env)
+	}
+
+	var result float64
+	for i, seg := range segs {
+		for j, v := range seg.values {
+			if i == 0 && j == 0 {
+				result = v
+			} else {
+				// Adding and subtracting does not fail for float64 numbers.
+				result, _ = floatArithmetic{}.apply(seg.ops[j], result, v)
+			}
+		}
+	}
+	return result, nil
+}
+
+// segment is a sequence of terms of an expression evaluated by EvalParallel. op is the operator preceding
+// the first term, which is 0 for the first segment.
+type segment struct {
+	src []byte
+	op  token.Type
+
+	// values contains the value of every term once evaluated and ops the operator preceding it.
+	values []float64
+	ops    []token.Type
+}
+
+// split splits src into segments of at least segmentSize bytes. It returns nil if src contains a semicolon or
+// an equals sign, which a segment must not contain.
+func split(src []byte) []segment {
+	var segs []segment
+	start, op := 0, token.Type(0)
+	ok := splitTerms(src, func(off int) {
+		if off-start >= segmentSize {
+			segs = append(segs, segment{src: src[start:off], op: op})
+			start, op = off+1, operator(src[off])
+		}
+	})
+	if !ok {
+		return nil
+	}
+	return append(segs, segment{src: src[start:], op: op})
+}
+
+// eval evaluates the terms of seg copying it into buf, which is reused between calls. It reports false if a
+// term fails to evaluate.
+func (seg *segment) eval(buf *[]byte, env *Env) bool {
+	src := append((*buf)[:0], seg.src...)
+	*buf = src
+
+	// Evaluate the terms as statements of a script.
+	seg.ops = append(seg.ops, seg.op)
+	splitTerms(src, func(off int) {
+		seg.ops = append(seg.ops, operator(src[off]))
+		src[off] = ';'
+	})
+
+	s := syntax.NewScanner(bytes.NewReader(src))
+	s.ValuesOnly()
+	c := rpn.New(s)
+	c.Script()
+
+	values, err := runRPN(c, env)
+	if err != nil || len(values) != len(seg.ops) {
+		// Some term is invalid, such as an empty one or one missing an operator.
+		return false
+	}
+	seg.values = values
+	return true
+}
+
+// splitTerms calls sep with the offset of every + and - in src which is a binary operator outside of any
+// parenthesis. It reports false if src contains a semicolon or an equals sign, which are invalid in an
+// expression. For invalid input, sep may be called for other offsets, too.
+func splitTerms(src []byte, sep func(off int)) bool {
+	depth := 0
+	// operand is true if the last rune other than whitespace ends an operand.
+	operand := false
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRune(src[i:])
+			if !unicode.IsSpace(r) {
+				// A letter or a digit of another script.
+				operand = true
+			}
+			i += size
+			continue
+		}
+		i++
+
+		switch {
+		case c == '+' || c == '-':
+			if operand && depth == 0 {
+				sep(i - 1)
+			}
+			operand = false
+		case c == '(':
+			depth++
+			operand = false
+		case c == ')':
+			depth--
+			operand = true
+		case c == ';' || c == '=':
+			return false
+		case c == '.' || c == '_' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z'):
+			operand = true
+		case c == '\b' || unicode.IsSpace(rune(c)):
+		default:
+			operand = false
+		}
+	}
+
+	return true
+}
+
+// operator returns the type of the operator c, which is + or -.
+func operator(c byte) token.Type {
+	if c == '+' {
+		return token.Add
+	}
+	return token.Sub
+}
@@ -0,0 +1,108 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/halimath/calc/token"
+)
+
+// Program is an expression compiled by an Engine to bytecode, which may be evaluated any number of times using
+// different values for its variables. Its syntax has been checked when compiling it, so evaluating it only
+// reports *EvalErrors and *UndefinedErrors. A Program may be used concurrently.
+type Program struct {
+	// vars contains the first occurrence of each variable and slots maps the name of each variable to its index
+	// in vars, which is the argument of the instructions loading it.
+	vars  []variable
+	slots map[string]int
+
+	code bytecode
+}
+
+// variable is the name of a variable along with the span of its first occurrence.
+type variable struct {
+	name string
+	span token.Span
+}
+
+func newProgram() *Program {
+	return &Program{slots: make(map[string]int)}
+}
+
+// declare returns the index of the variable name, adding it if this is its first occurrence.
+func (p *Program) declare(name string, span token.Span) int {
+	slot, ok := p.slots[name]
+	if !ok {
+		slot = len(p.vars)
+		p.slots[name] = slot
+		p.vars = append(p.vars, variable{name: name, span: span})
+	}
+	return slot
+}
+
+// Vars returns the names of the variables referred to by p in order of their first occurrence.
+func (p *Program) Vars() []string {
+	names := make([]string, len(p.vars))
+	for i, v := range p.vars {
+		names[i] = v.name
+	}
+	return names
+}
+
+// Eval evaluates p using vars as the values of its variables. It reports an *UndefinedError for the first
+// variable missing from vars before evaluating anything.
+func (p *Program) Eval(vars map[string]float64) (float64, error) {
+	values := make([]float64, len(p.vars))
+	for i, v := range p.vars {
+		var ok bool
+		if values[i], ok = vars[v.name]; !ok {
+			return 0, &UndefinedError{Name: v.name, Span: v.span}
+		}
+	}
+
+	return p.code.run(values)
+}
+
+// Bind binds the variables of p to positions in a slice of values: the value of the variable names[i] is
+// expected at index i. Names not referred to by p are ignored, so several programs may be bound to the same
+// layout of values. Bind reports an *UndefinedError for the first variable of p missing from names.
+func (p *Program) Bind(names ...string) (*Binding, error) {
+	index := make([]uint32, len(p.vars))
+	for i, v := range p.vars {
+		j := slices.Index(names, v.name)
+		if j < 0 {
+			return nil, &UndefinedError{Name: v.name, Span: v.span}
+		}
+		index[i] = uint32(j)
+	}
+
+	// Rewrite the instructions loading variables to load them from their position in values.
+	code := p.code
+	code.code = slices.Clone(code.code)
+	for i, in := range code.code {
+		if in.op == opLoad {
+			code.code[i].arg = index[in.arg]
+		}
+	}
+
+	return &Binding{code: code, n: len(names)}, nil
+}
+
+// Binding is a Program whose variables have been bound to positions in a slice of values using Program.Bind.
+// Evaluating a Binding resolves variables by their position rather than by their name. A Binding may be used
+// concurrently.
+type Binding struct {
+	code bytecode
+	// n is the number of names passed to Bind.
+	n int
+}
+
+// Eval evaluates the program using values, which contains the value of each name passed to Bind at the same
+// index.
+func (b *Binding) Eval(values []float64) (float64, error) {
+	if len(values) != b.n {
+		return 0, fmt.Errorf("%w: expected %d values but got %d", ErrInvalidInput, b.n, len(values))
+	}
+
+	return b.code.run(values)
+}
@@ -0,0 +1,403 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/halimath/calc/decimal"
+	"github.com/halimath/calc/rpn"
+	"github.com/halimath/calc/stack"
+	"github.com/halimath/calc/syntax"
+	"github.com/halimath/calc/token"
+)
+
+// RPN is an Engine converting expressions to reverse polish notation using an rpn.RPN and evaluating the
+// resulting tokens using a stack of operands. It detects missing operands only when applying an operator and
+// reports them as syntax.SyntaxError of kind syntax.MissingOperand.
+//
+// Operators are applied as soon as their precedence allows, so evaluating takes memory proportional to the
+// nesting depth of the input and to the number of operators pending because of their precedence rather than
+// to the length of the input. A flat sum, for example, is evaluated in constant memory whatever its length.
+type RPN struct{}
+
+func (RPN) Name() string { return "rpn" }
+
+func (RPN) Eval(s *syntax.Scanner, env *Env) (float64, error) {
+	s.ValuesOnly()
+	return evalRPN[float64](floatArithmetic{}, rpn.New(s), env)
+}
+
+func (RPN) EvalBig(s *syntax.Scanner, prec uint) (*big.Float, error) {
+	return evalRPN[*big.Float](bigFloatArithmetic{prec: bigPrec(prec)}, rpn.New(s), nil)
+}
+
+func (RPN) EvalRat(s *syntax.Scanner) (*big.Rat, error) {
+	return evalRPN[*big.Rat](ratArithmetic{}, rpn.New(s), nil)
+}
+
+func (RPN) EvalDecimal(s *syntax.Scanner, ctx decimal.Context) (decimal.Decimal, error) {
+	return evalRPN[decimal.Decimal](decimalArithmetic{ctx: ctx}, rpn.New(s), nil)
+}
+
+func (RPN) Compile(s *syntax.Scanner, env *Env) (*Program, error) {
+	s.ValuesOnly()
+	return CompileRPN(rpn.New(s), env)
+}
+
+func (e RPN) Check(s *syntax.Scanner, max int) error {
+	return e.check(rpn.New(s), max)
+}
+
+// check checks the tokens converted by c in recovery mode.
+func (RPN) check(c *rpn.RPN, max int) error {
+	c.Recover(max)
+
+	for {
+		if _, err := c.Next(); err != nil {
+			break
+		}
+	}
+
+	if errs := c.Errors(); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (RPN) Run(s *syntax.Scanner, env *Env) ([]float64, error) {
+	s.Script()
+	s.ValuesOnly()
+	c := rpn.New(s)
+	c.Script()
+	return runRPN(c, env)
+}
+
+func (e RPN) CheckScript(s *syntax.Scanner, max int) error {
+	s.Script()
+	c := rpn.New(s)
+	c.Script()
+	return e.check(c, max)
+}
+
+// EvalRPN evaluates the tokens read from tr using float64 numbers. The tokens must be in reverse polish
+// notation, such as those yielded by an rpn.RPN. It reports an *UndefinedError for any variable.
+func EvalRPN(tr token.Reader) (float64, error) {
+	return evalRPN[float64](floatArithmetic{}, tr, nil)
+}
+
+// CompileRPN compiles the tokens read from tr into a Program. The tokens must be in reverse polish notation,
+// such as those yielded by an rpn.RPN. The program calls the functions and uses the constants defined by env,
+// which may be nil. Missing operands are reported when compiling rather than when evaluating the program.
+func CompileRPN(tr token.Reader, env *Env) (*Program, error) {
+	p := newProgram()
+	e := newEmitter()
+
+	end := 0
+
+	for {
+		tok, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+		}
+
+		end = max(end, tok.End())
+		span := spanOf(tr, tok)
+
+		switch {
+		case tok.Type == token.Number:
+			e.constant(tok.Value, span)
+		case tok.Type == token.Ident:
+			if v, ok := env.constant(tok.Literal); ok {
+				e.constant(v, span)
+			} else {
+				e.load(p.declare(tok.Literal, span), span)
+			}
+		case token.IsOperator(tok):
+			if e.size < 2 {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidInput, missingOperand(tr, tok))
+			}
+			e.binary(tok.Type, span)
+		case token.IsUnary(tok):
+			if e.size < 1 {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidInput, missingOperand(tr, tok))
+			}
+			if tok.Type == token.Neg {
+				e.neg(span)
+			}
+		case tok.Type == token.Call:
+			if e.size < tok.Arity {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidInput, missingOperand(tr, tok))
+			}
+			fn, err := env.function(tok.Literal, tok.Arity, span)
+			if err != nil {
+				return nil, err
+			}
+			e.call(fn, tok.Arity, span)
+		default:
+			return nil, fmt.Errorf("%w: %s: unexpected token: %v", ErrInvalidInput, span, tok)
+		}
+	}
+
+	if e.size == 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidInput, emptyExpression(tr, end))
+	}
+	if e.size > 1 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidInput, missingOperator(tr, end))
+	}
+
+	p.code = e.b
+	return p, nil
+}
+
+// evalRPN evaluates the tokens in RPN read from tr using a. It calls the functions and uses the constants
+// defined by env, which may be nil. Variables are only supported by compiled programs, so it reports an
+// *UndefinedError for any other identifier.
+func evalRPN[T any](a arithmetic[T], tr token.Reader, env *Env) (T, error) {
+	return evalRPNStack(a, tr, env, make(stack.Stack[T], 0, 64))
+}
+
+// evalRPNStack implements evalRPN using operands, which must be empty, as the stack of operands, so callers
+// evaluating many expressions may reuse it.
+func evalRPNStack[T any](a arithmetic[T], tr token.Reader, env *Env, operands stack.Stack[T]) (T, error) {
+	var zero T
+
+	// end is the offset following the last token, used to report an empty expression.
+	end := 0
+
+	for {
+		tok, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return zero, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+		}
+
+		end = max(end, tok.End())
+
+		if tok.Type == token.Number {
+			v, err := a.number(tok)
+			if err != nil {
+				return zero, fmt.Errorf("%w: %w", ErrInvalidInput, &syntax.ScanError{Kind: syntax.MalformedNumber, Span: spanOf(tr, tok), Text: tok.Literal, Err: err})
+			}
+			operands.Push(v)
+			continue
+		}
+
+		if tok.Type == token.Ident {
+			c, ok := env.constant(tok.Literal)
+			if !ok {
+				return zero, &UndefinedError{Name: tok.Literal, Span: spanOf(tr, tok)}
+			}
+			v, err := a.number(constToken(tok.Literal, c, tok.Offset))
+			if err != nil {
+				return zero, err
+			}
+			operands.Push(v)
+			continue
+		}
+
+		if token.IsOperator(tok) {
+			if len(operands) < 2 {
+				return zero, fmt.Errorf("%w: %w", ErrInvalidInput, missingOperand(tr, tok))
+			}
+
+			l := operands.Pop()
+			r := operands.Pop()
+
+			v, err := a.apply(tok.Type, r, l)
+			if err != nil {
+				return zero, newEvalError(spanOf(tr, tok), tok.Type, err, r, l)
+			}
+			operands.Push(v)
+
+			continue
+		}
+
+		if token.IsUnary(tok) {
+			if operands.Empty() {
+				return zero, fmt.Errorf("%w: %w", ErrInvalidInput, missingOperand(tr, tok))
+			}
+
+			if tok.Type == token.Neg {
+				operands.Push(a.neg(operands.Pop()))
+			}
+
+			continue
+		}
+
+		if tok.Type == token.Call {
+			if len(operands) < tok.Arity {
+				return zero, fmt.Errorf("%w: %w", ErrInvalidInput, missingOperand(tr, tok))
+			}
+
+			base := len(operands) - tok.Arity
+			v, err := call(a, env, tok.Literal, spanOf(tr, tok), operands[base:])
+			if err != nil {
+				return zero, err
+			}
+			operands = append(operands[:base], v)
+
+			continue
+		}
+
+		return zero, fmt.Errorf("%w: %s: unexpected token: %v", ErrInvalidInput, spanOf(tr, tok), tok)
+	}
+
+	if operands.Empty() {
+		return zero, fmt.Errorf("%w: %w", ErrInvalidInput, emptyExpression(tr, end))
+	}
+	if len(operands) > 1 {
+		return zero, fmt.Errorf("%w: %w", ErrInvalidInput, missingOperator(tr, end))
+	}
+
+	return operands.Pop(), nil
+}
+
+// runRPN runs the script in RPN read from tr, such as the tokens yielded by an rpn.RPN in script mode, using
+// float64 numbers and returns the value of every statement. It calls the functions and uses the constants
+// defined by env, which may be nil.
+func runRPN(tr token.Reader, env *Env) ([]float64, error) {
+	scope := env.scope()
+	var values []float64
+
+	// Reuse the statement and the operands for every statement rather than allocating them each time.
+	var stmt statement
+	operands := make(stack.Stack[float64], 0, 64)
+
+	for {
+		stmt = statement{tr: tr}
+		v, err := evalRPNStack[float64](floatArithmetic{}, &stmt, scope, operands[:0])
+		if stmt.eof && stmt.n == 0 {
+			// The previous statement has been the last one.
+			return values, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if stmt.assign != "" {
+			scope.consts[stmt.assign] = v
+		}
+		values = append(values, v)
+	}
+}
+
+// statement is a token.Reader yielding the tokens of the next statement of a script in RPN read from tr. It
+// returns io.EOF at the token.Semicolon terminating the statement and records a token.Assign preceding it.
+type statement struct {
+	tr token.Reader
+	// n is the number of tokens yielded so far and eof is true once tr is exhausted.
+	n   int
+	eof bool
+	// assign is the name of the variable assigned by the statement, if any.
+	assign string
+	// end is the offset of the token.Semicolon terminating the statement if ended is true.
+	end   int
+	ended bool
+}
+
+func (s *statement) Next() (token.Token, error) {
+	tok, err := s.tr.Next()
+	switch {
+	case errors.Is(err, io.EOF):
+		s.eof = true
+		return tok, err
+	case err != nil:
+		return tok, err
+	case tok.Type == token.Semicolon:
+		s.end, s.ended = tok.Offset, true
+		return token.Token{Offset: tok.Offset}, io.EOF
+	case tok.Type == token.Assign:
+		if s.n == 0 {
+			return token.Token{}, missingOperand(s, tok)
+		}
+		s.assign = tok.Literal
+		return s.Next()
+	}
+
+	s.n++
+	return tok, nil
+}
+
+// Pos returns the position of offset as derived by the underlying token.Reader.
+func (s *statement) Pos(offset int) token.Pos {
+	if l, ok := s.tr.(token.Locator); ok {
+		return l.Pos(offset)
+	}
+	return token.Pos{Offset: offset}
+}
+
+// Offset returns the offset of the token.Semicolon terminating the statement once it has been read, and the
+// offset following the last token read by the underlying token.Reader otherwise.
+func (s *statement) Offset() int {
+	if s.ended {
+		return s.end
+	}
+	if l, ok := s.tr.(token.Locator); ok {
+		return l.Offset()
+	}
+	return 0
+}
+
+// spanOf returns the span of tok read from tr. Positions are derived using tr if it is a token.Locator, so this
+// is only done when reporting an error. Otherwise they carry the offset only.
+func spanOf(tr token.Reader, tok token.Token) token.Span {
+	return spanAt(tr, tok.Offset, tok.End())
+}
+
+// spanAt returns the span from start to end of the input of tr.
+func spanAt(tr token.Reader, start, end int) token.Span {
+	l, ok := tr.(token.Locator)
+	if !ok {
+		return token.Span{Start: token.Pos{Offset: start}, End: token.Pos{Offset: end}}
+	}
+	return token.Span{Start: l.Pos(start), End: l.Pos(end)}
+}
+
+// endOf returns the offset following the last token read from tr, which is at least end.
+func endOf(tr token.Reader, end int) int {
+	if l, ok := tr.(token.Locator); ok {
+		return max(end, l.Offset())
+	}
+	return end
+}
+
+// emptyExpression creates the error to return for an expression without any operand, which ends at end.
+func emptyExpression(tr token.Reader, end int) error {
+	end = endOf(tr, end)
+	return &syntax.SyntaxError{
+		Kind:     syntax.MissingOperand,
+		Span:     spanAt(tr, end, end),
+		Expected: operandTokens,
+	}
+}
+
+// missingOperator creates the error to return when operands are left without an operator combining them at
+// the end of input following end.
+func missingOperator(tr token.Reader, end int) error {
+	end = endOf(tr, end)
+	return &syntax.SyntaxError{
+		Kind:     syntax.UnexpectedToken,
+		Span:     spanAt(tr, end, end),
+		Expected: []string{"+", "-", "*", "/", "^"},
+	}
+}
+
+// operandTokens lists the tokens that may start an operand.
+var operandTokens = []string{"number", "identifier", "(", "+", "-"}
+
+// missingOperand creates the error to return when there are not enough operands to apply op read from tr to.
+func missingOperand(tr token.Reader, op token.Token) error {
+	return &syntax.SyntaxError{
+		Kind:     syntax.MissingOperand,
+		Span:     spanOf(tr, op),
+		Found:    op.String(),
+		Expected: operandTokens,
+	}
+}
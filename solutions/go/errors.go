@@ -0,0 +1,82 @@
+package calc
+
+import (
+	"github.com/halimath/calc/engine"
+	"github.com/halimath/calc/syntax"
+	"github.com/halimath/calc/token"
+)
+
+var (
+	ErrInvalidInput    = engine.ErrInvalidInput
+	ErrDivisionByZero  = engine.ErrDivisionByZero
+	ErrDomain          = engine.ErrDomain
+	ErrUndefined       = engine.ErrUndefined
+	ErrUnknownFunction = engine.ErrUnknownFunction
+	ErrArity           = engine.ErrArity
+)
+
+type (
+	// Pos describes a position in the input.
+	Pos = token.Pos
+	// Span describes a contiguous range of input.
+	Span = token.Span
+)
+
+type (
+	// ScanError describes input that does not form a valid token. It wraps syntax.ErrScanFailed.
+	ScanError = syntax.ScanError
+	// ScanErrorKind classifies ScanErrors.
+	ScanErrorKind = syntax.ScanErrorKind
+)
+
+const (
+	InvalidRune     = syntax.InvalidRune
+	MalformedNumber = syntax.MalformedNumber
+	ReadFailed      = syntax.ReadFailed
+
+	LeadingZero       = syntax.LeadingZero
+	LeadingDot        = syntax.LeadingDot
+	TrailingDot       = syntax.TrailingDot
+	RepeatedDot       = syntax.RepeatedDot
+	NonASCIIDigit     = syntax.NonASCIIDigit
+	InvalidWhitespace = syntax.InvalidWhitespace
+)
+
+type (
+	// SyntaxError describes tokens that do not form a valid expression. It wraps syntax.ErrInvalidSyntax.
+	SyntaxError = syntax.SyntaxError
+	// SyntaxErrorKind classifies SyntaxErrors.
+	SyntaxErrorKind = syntax.SyntaxErrorKind
+	// ErrorList is a list of ScanErrors and SyntaxErrors returned by Check.
+	ErrorList = syntax.ErrorList
+)
+
+const (
+	UnexpectedToken = syntax.UnexpectedToken
+	UnclosedParen   = syntax.UnclosedParen
+	MissingOperand  = syntax.MissingOperand
+)
+
+type (
+	// UndefinedError describes a reference to a variable without a value.
+	UndefinedError = engine.UndefinedError
+	// CallError describes a call of an unknown function or a call with the wrong number of arguments.
+	CallError = engine.CallError
+	// EvalError describes an operation that failed during evaluation.
+	EvalError = engine.EvalError
+	// EvalErrorKind classifies EvalErrors.
+	EvalErrorKind = engine.EvalErrorKind
+)
+
+const (
+	DivisionByZero = engine.DivisionByZero
+	Domain         = engine.Domain
+	Unsupported    = engine.Unsupported
+)
+
+type (
+	// Program is a compiled expression returned by Compile.
+	Program = engine.Program
+	// Binding is a Program whose variables have been bound to positions in a slice of values.
+	Binding = engine.Binding
+)
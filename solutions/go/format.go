@@ -0,0 +1,114 @@
+package calc
+
+import (
+	"math/big"
+	"strings"
+)
+
+// FormatFraction formats x as an exact fraction in lowest terms, such as "-7/3". Integral values are
+// formatted without a denominator.
+func FormatFraction(x *big.Rat) string {
+	return x.RatString()
+}
+
+// FormatRounded formats x as a decimal number rounded to places fractional digits. The last digit is
+// rounded half away from zero.
+func FormatRounded(x *big.Rat, places int) string {
+	return x.FloatString(places)
+}
+
+// FormatRepeating formats x as an exact decimal number with the repeating part of its fraction enclosed in
+// parenthesis, such as "0.1(6)" for 1/6. Terminating fractions are formatted without parenthesis.
+//
+// The number of fractional digits is limited to maxDigits; if the fraction does not terminate or repeat
+// within that limit, the digits are truncated and followed by "...". A negative maxDigits disables the
+// limit, which may produce very long output for large denominators.
+func FormatRepeating(x *big.Rat, maxDigits int) string {
+	var b strings.Builder
+
+	if x.Sign() < 0 {
+		b.WriteByte('-')
+	}
+
+	den := x.Denom()
+	q, r := new(big.Int).QuoRem(new(big.Int).Abs(x.Num()), den, new(big.Int))
+	b.WriteString(q.String())
+
+	if r.Sign() == 0 {
+		return b.String()
+	}
+
+	b.WriteByte('.')
+
+	ten := big.NewInt(10)
+	d := new(big.Int)
+	digits := make([]byte, 0, 32)
+
+	// next appends the next fractional digit using long division.
+	next := func() bool {
+		if maxDigits >= 0 && len(digits) == maxDigits {
+			return false
+		}
+		r.Mul(r, ten)
+		d.QuoRem(r, den, r)
+		digits = append(digits, byte('0'+d.Int64()))
+		return true
+	}
+
+	// The fraction's digits are periodic once the factors 2 and 5 of den have been consumed. As the
+	// remainders are periodic from that point on, the period ends when the first remainder shows up again.
+	for n := prePeriod(den); n > 0; n-- {
+		if !next() {
+			b.Write(digits)
+			b.WriteString("...")
+			return b.String()
+		}
+	}
+
+	if r.Sign() == 0 {
+		b.Write(digits)
+		return b.String()
+	}
+
+	start := len(digits)
+	first := new(big.Int).Set(r)
+
+	for {
+		if !next() {
+			b.Write(digits)
+			b.WriteString("...")
+			return b.String()
+		}
+
+		if r.Cmp(first) == 0 {
+			break
+		}
+	}
+
+	b.Write(digits[:start])
+	b.WriteByte('(')
+	b.Write(digits[start:])
+	b.WriteByte(')')
+
+	return b.String()
+}
+
+// prePeriod returns the number of fractional digits of 1/den before the repeating part starts, which is
+// the larger of the exponents of the factors 2 and 5 in den.
+func prePeriod(den *big.Int) int {
+	twos := int(den.TrailingZeroBits())
+
+	fives := 0
+	five := big.NewInt(5)
+	v, q, m := new(big.Int).Set(den), new(big.Int), new(big.Int)
+	for {
+		q.QuoRem(v, five, m)
+		if m.Sign() != 0 {
+			break
+		}
+		v, q = q, v
+		fives++
+	}
+
+	return max(twos, fives)
+}
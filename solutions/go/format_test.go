@@ -0,0 +1,63 @@
+package calc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/halimath/expect"
+	"github.com/halimath/expect/is"
+)
+
+func TestFormatRepeating(t *testing.T) {
+	type testCase struct {
+		in        string
+		maxDigits int
+		want      string
+	}
+
+	tests := []testCase{
+		{in: "0", maxDigits: 10, want: "0"},
+		{in: "42", maxDigits: 10, want: "42"},
+		{in: "-42", maxDigits: 10, want: "-42"},
+		{in: "1/2", maxDigits: 10, want: "0.5"},
+		{in: "-1/8", maxDigits: 10, want: "-0.125"},
+		{in: "1/3", maxDigits: 10, want: "0.(3)"},
+		{in: "-4/3", maxDigits: 10, want: "-1.(3)"},
+		{in: "1/6", maxDigits: 10, want: "0.1(6)"},
+		{in: "1/7", maxDigits: 10, want: "0.(142857)"},
+		{in: "22/7", maxDigits: 10, want: "3.(142857)"},
+		{in: "1/12", maxDigits: 10, want: "0.08(3)"},
+		{in: "1/11", maxDigits: 10, want: "0.(09)"},
+		{in: "1/250", maxDigits: 10, want: "0.004"},
+		{in: "7/440", maxDigits: 10, want: "0.015(90)"},
+		{in: "1/7", maxDigits: 6, want: "0.(142857)"},
+		{in: "1/7", maxDigits: 4, want: "0.1428..."},
+		{in: "1/1024", maxDigits: 4, want: "0.0009..."},
+		{in: "1/1024", maxDigits: -1, want: "0.0009765625"},
+		{in: "1/97", maxDigits: -1, want: "0.(010309278350515463917525773195876288659793814432989690721649484536082474226804123711340206185567)"},
+	}
+
+	for _, test := range tests {
+		x, _ := new(big.Rat).SetString(test.in)
+		expect.WithMessage(t, "in: %s", test.in).That(
+			is.EqualTo(FormatRepeating(x, test.maxDigits), test.want),
+		)
+	}
+}
+
+func TestFormatFraction(t *testing.T) {
+	expect.That(t,
+		is.EqualTo(FormatFraction(big.NewRat(3, 10)), "3/10"),
+		is.EqualTo(FormatFraction(big.NewRat(-14, 6)), "-7/3"),
+		is.EqualTo(FormatFraction(big.NewRat(4, 2)), "2"),
+	)
+}
+
+func TestFormatRounded(t *testing.T) {
+	expect.That(t,
+		is.EqualTo(FormatRounded(big.NewRat(2, 3), 2), "0.67"),
+		is.EqualTo(FormatRounded(big.NewRat(-1, 8), 2), "-0.13"),
+		is.EqualTo(FormatRounded(big.NewRat(1, 3), 0), "0"),
+		is.EqualTo(FormatRounded(big.NewRat(5, 1), 3), "5.000"),
+	)
+}
@@ -0,0 +1,111 @@
+// Package queue provides a generic implementation of a first-in-first-out queue abstract datastructure.
+package queue
+
+// minCapacity is the capacity a Queue allocates when the first element is pushed and below which it does not
+// shrink.
+const minCapacity = 16
+
+// Queue implements a queue of elements of type T using a ring buffer. The buffer doubles its capacity when
+// pushing to a full Queue. It halves its capacity once no more than a quarter of it has been in use for as
+// many calls to Shift as it has room for elements, so a Queue releases memory not needed any more without
+// reallocating for every burst of elements pushed. The default value for Queue is ready for use, though
+// empty.
+type Queue[T any] struct {
+	// buf contains the elements starting at index head and wrapping around at its end. Its length is zero or
+	// a power of two.
+	buf  []T
+	head int
+	len  int
+	// idle counts the calls to Shift leaving no more than a quarter of buf in use in a row.
+	idle int
+}
+
+// New creates a new Queue allocating room for at least capacity elements.
+func New[T any](capacity int) *Queue[T] {
+	c := minCapacity
+	for c < capacity {
+		c *= 2
+	}
+	return &Queue[T]{buf: make([]T, c)}
+}
+
+// Empty returns whether q is empty, i.e. contains no element.
+func (q *Queue[T]) Empty() bool { return q.len == 0 }
+
+// Len returns the number of elements in q.
+func (q *Queue[T]) Len() int { return q.len }
+
+// Push appends v to the end of q.
+func (q *Queue[T]) Push(v T) {
+	if q.len == len(q.buf) {
+		q.resize(max(2*len(q.buf), minCapacity))
+	}
+
+	q.buf[(q.head+q.len)&(len(q.buf)-1)] = v
+	q.len++
+}
+
+// Peek returns the first element of q without removing it. It panics if q is empty.
+func (q *Queue[T]) Peek() T {
+	if q.len == 0 {
+		panic("empty queue")
+	}
+
+	return q.buf[q.head]
+}
+
+// Shift removes the first element from q and returns it. It panics if q is empty.
+func (q *Queue[T]) Shift() T {
+	if q.len == 0 {
+		panic("empty queue")
+	}
+
+	var zero T
+	v := q.buf[q.head]
+	// Clear the slot so q does not keep the element from being garbage collected.
+	q.buf[q.head] = zero
+	q.head = (q.head + 1) & (len(q.buf) - 1)
+	q.len--
+
+	if len(q.buf) > minCapacity && q.len <= len(q.buf)/4 {
+		q.idle++
+		if q.idle >= len(q.buf) {
+			q.resize(len(q.buf) / 2)
+		}
+	} else {
+		q.idle = 0
+	}
+
+	return v
+}
+
+// Clear removes all elements from q keeping its capacity.
+func (q *Queue[T]) Clear() {
+	clear(q.buf)
+	q.head, q.len, q.idle = 0, 0, 0
+}
+
+// All returns an iterator over the elements of q from first to last, which yields the index of each element
+// along with it. It has the type of an iter.Seq2[int, T]. q must not be modified while iterating.
+func (q *Queue[T]) All() func(yield func(int, T) bool) {
+	return func(yield func(int, T) bool) {
+		for i := 0; i < q.len; i++ {
+			if !yield(i, q.buf[(q.head+i)&(len(q.buf)-1)]) {
+				return
+			}
+		}
+	}
+}
+
+// resize moves the elements of q to a new buffer of size c.
+func (q *Queue[T]) resize(c int) {
+	buf := make([]T, c)
+	if end := q.head + q.len; end <= len(q.buf) {
+		copy(buf, q.buf[q.head:end])
+	} else {
+		n := copy(buf, q.buf[q.head:])
+		copy(buf[n:], q.buf[:end-len(q.buf)])
+	}
+	q.buf = buf
+	q.head, q.idle = 0, 0
+}
@@ -0,0 +1,163 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/halimath/calc/stack"
+	"github.com/halimath/expect"
+	"github.com/halimath/expect/is"
+)
+
+func TestQueue(t *testing.T) {
+	var q Queue[int]
+
+	expect.That(t, is.EqualTo(q.Empty(), true))
+
+	q.Push(1)
+	expect.That(t, is.EqualTo(q.Empty(), false))
+
+	q.Push(2)
+	q.Push(3)
+	expect.That(t, is.EqualTo(q.Len(), 3))
+
+	v := q.Peek()
+	expect.That(t, is.EqualTo(v, 1))
+	expect.That(t, is.EqualTo(q.Len(), 3))
+
+	v = q.Shift()
+	expect.That(t, is.EqualTo(v, 1))
+	expect.That(t, is.EqualTo(q.Len(), 2))
+
+	v = q.Shift()
+	expect.That(t, is.EqualTo(v, 2))
+	expect.That(t, is.EqualTo(q.Len(), 1))
+
+	q.Clear()
+	expect.That(t, is.EqualTo(q.Empty(), true))
+
+	q.Push(4)
+	v = q.Shift()
+	expect.That(t, is.EqualTo(v, 4))
+	expect.That(t, is.EqualTo(q.Len(), 0))
+}
+
+func TestQueue_wrapAround(t *testing.T) {
+	q := New[int](0)
+
+	// Move head close to the end of the buffer, so the elements wrap around before the buffer grows and
+	// shrinks again.
+	for i := 0; i < minCapacity-2; i++ {
+		q.Push(i)
+		q.Shift()
+	}
+
+	for i := 0; i < 10*minCapacity; i++ {
+		q.Push(i)
+	}
+	expect.That(t, is.EqualTo(len(q.buf), 16*minCapacity))
+
+	for i := 0; i < 10*minCapacity; i++ {
+		expect.That(t, is.EqualTo(q.Shift(), i))
+	}
+	expect.That(t,
+		is.EqualTo(q.Empty(), true),
+		is.EqualTo(len(q.buf), 16*minCapacity),
+	)
+}
+
+func TestQueue_shrink(t *testing.T) {
+	var q Queue[int]
+	for i := 0; i < 64*minCapacity; i++ {
+		q.Push(i)
+	}
+	for !q.Empty() {
+		q.Shift()
+	}
+	expect.That(t, is.EqualTo(len(q.buf), 64*minCapacity))
+
+	// Short bursts keep the queue in low use until it has shrunk to the minimum.
+	for i := 0; i < 1000*minCapacity; i++ {
+		q.Push(i)
+		q.Push(i)
+		q.Shift()
+		q.Shift()
+	}
+	expect.That(t, is.EqualTo(len(q.buf), minCapacity))
+
+	// Bursts filling up the queue keep it from shrinking.
+	for i := 0; i < 1000; i++ {
+		for j := 0; j < 2*minCapacity; j++ {
+			q.Push(j)
+		}
+		for j := 0; j < 2*minCapacity; j++ {
+			expect.That(t, is.EqualTo(q.Shift(), j))
+		}
+	}
+	expect.That(t, is.EqualTo(len(q.buf), 2*minCapacity))
+}
+
+func TestQueue_All(t *testing.T) {
+	var q Queue[string]
+	for _, s := range []string{"x", "a", "b", "c"} {
+		q.Push(s)
+	}
+	q.Shift()
+
+	var got []string
+	q.All()(func(i int, s string) bool {
+		expect.That(t, is.EqualTo(i, len(got)))
+		got = append(got, s)
+		return true
+	})
+	expect.That(t, is.DeepEqualTo(got, []string{"a", "b", "c"}))
+
+	got = nil
+	q.All()(func(_ int, s string) bool {
+		got = append(got, s)
+		return len(got) < 2
+	})
+	expect.That(t, is.DeepEqualTo(got, []string{"a", "b"}))
+}
+
+func TestQueue_panics(t *testing.T) {
+	var q Queue[int]
+
+	for _, f := range []func(){func() { q.Peek() }, func() { q.Shift() }} {
+		func() {
+			defer func() {
+				expect.That(t, is.EqualTo(recover(), any("empty queue")))
+			}()
+			f()
+		}()
+	}
+}
+
+// BenchmarkQueue compares a Queue to a stack.Stack used as a queue, which reslices when shifting. Elements
+// are pushed and shifted in bursts of increasing size, like an rpn.RPN does.
+func BenchmarkQueue(b *testing.B) {
+	b.Run("queue", func(b *testing.B) {
+		b.ReportAllocs()
+		var q Queue[int]
+		for n := 0; n < b.N; n++ {
+			for i := 0; i < n%64; i++ {
+				q.Push(i)
+			}
+			for !q.Empty() {
+				q.Shift()
+			}
+		}
+	})
+
+	b.Run("slice", func(b *testing.B) {
+		b.ReportAllocs()
+		var s stack.Stack[int]
+		for n := 0; n < b.N; n++ {
+			for i := 0; i < n%64; i++ {
+				s.Push(i)
+			}
+			for !s.Empty() {
+				s.Shift()
+			}
+		}
+	})
+}
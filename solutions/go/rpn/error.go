@@ -0,0 +1,38 @@
+package rpn
+
+import (
+	"errors"
+
+	"github.com/halimath/calc/syntax"
+	"github.com/halimath/calc/token"
+)
+
+// operandTokens lists the tokens that may start an operand.
+var operandTokens = []string{"number", "identifier", "(", "+", "-"}
+
+// missingOperand creates the error to report when there are not enough operands to apply op to.
+func (rpn *RPN) missingOperand(op token.Token) *syntax.SyntaxError {
+	return &syntax.SyntaxError{
+		Kind:     syntax.MissingOperand,
+		Span:     rpn.tokenSpan(op),
+		Found:    op.String(),
+		Expected: operandTokens,
+	}
+}
+
+// offset returns the offset of the input err refers to.
+func offset(err error) int {
+	var (
+		scanErr   *syntax.ScanError
+		syntaxErr *syntax.SyntaxError
+	)
+
+	switch {
+	case errors.As(err, &scanErr):
+		return scanErr.Span.Start.Offset
+	case errors.As(err, &syntaxErr):
+		return syntaxErr.Span.Start.Offset
+	default:
+		return 0
+	}
+}
@@ -1,5 +0,0 @@
-module github.com/halimath/calc
-
-go 1.22
-
-require github.com/halimath/expect v0.5.0
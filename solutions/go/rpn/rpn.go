@@ -0,0 +1,459 @@
+// Package rpn provides a type that converts mathematical expressions in infix notation to reverse polish
+// notation (RPN). It implements the [shunting yard algorithm] as defined by Edsger Dijkstra. Function calls
+// are yielded as a token.Call following their arguments, i.e. max(1, 2) becomes 1 2 max/2.
+//
+// [shunting yard algorithm]: https://en.wikipedia.org/wiki/Shunting_yard_algorithm
+package rpn
+
+import (
+	"errors"
+	"io"
+	"slices"
+
+	"github.com/halimath/calc/queue"
+	"github.com/halimath/calc/stack"
+	"github.com/halimath/calc/syntax"
+	"github.com/halimath/calc/token"
+)
+
+// RPN implements a type to consume token.Token from a syntax.Scanner assuming these to be in infix notation
+// and transforms them to reverse polish notation.
+type RPN struct {
+	s         *syntax.Scanner
+	// out and operators start empty and grow as needed, so converting short input allocates little.
+	out       queue.Queue[token.Token]
+	operators stack.Stack[token.Token]
+
+	// operand is true whenever the next token is expected to start an operand. An Add or Sub token in that
+	// position is a prefix sign rather than a binary operator.
+	operand bool
+
+	// groups contains an entry for every open parenthesis: the number of commas separating the arguments of a
+	// call read so far and -1 for other parenthesis. Calls are kept on operators below their opening
+	// parenthesis. prev is the type of the previous token.
+	groups stack.Stack[int]
+	prev   token.Type
+
+	// ahead is true if the token following an identifier has been read ahead into aheadTok and aheadErr in
+	// order to tell a variable from a call.
+	ahead    bool
+	aheadTok token.Token
+	aheadErr error
+
+	// recovering enables recovery mode, in which up to max errors (or any number if max < 1) are collected in
+	// errs. depth counts the operands an evaluation of the tokens yielded so far would leave on the stack and
+	// end is the offset following the last token.
+	recovering bool
+	max        int
+	errs       syntax.ErrorList
+	depth      int
+	end        int
+
+	// script enables script mode.
+	script bool
+}
+
+// New creates a new RPN consuming tokens from s.
+func New(s *syntax.Scanner) *RPN {
+	return &RPN{
+		s:       s,
+		operand: true,
+	}
+}
+
+// Recover switches rpn to recovery mode. Instead of returning the first error, Next records it, skips the
+// offending input and continues. In addition, Next reports operators lacking an operand, which otherwise are
+// detected when evaluating the RPN. Once max errors have been recorded (unless max is less than 1), Next
+// returns io.EOF. Errors returns the recorded errors.
+func (rpn *RPN) Recover(max int) {
+	rpn.recovering = true
+	rpn.max = max
+}
+
+// Script switches rpn to script mode, in which it converts a sequence of statements separated by
+// token.Semicolon, such as x = 2; x * 3. Every statement is followed by a token.Semicolon, including the last
+// one, while empty statements are dropped. An assignment yields a token.Assign carrying the name of the
+// variable as its Literal following the value assigned, i.e. x = 2 becomes 2 x= ;. Script must be called
+// before the first call to Next.
+func (rpn *RPN) Script() {
+	rpn.script = true
+}
+
+// Pos returns the position of offset in the input of the scanner rpn consumes.
+func (rpn *RPN) Pos(offset int) token.Pos {
+	return rpn.s.Pos(offset)
+}
+
+// Offset returns the offset following the last token read from the scanner rpn consumes.
+func (rpn *RPN) Offset() int {
+	return rpn.s.Offset()
+}
+
+// Errors returns the errors recorded in recovery mode ordered by their position, or nil if there are none.
+func (rpn *RPN) Errors() syntax.ErrorList {
+	slices.SortStableFunc(rpn.errs, func(a, b error) int { return offset(a) - offset(b) })
+	return rpn.errs
+}
+
+// Next yields the next token in RPN or an error. If no more tokens are available it returns io.EOF. Errors are
+// prefixed with the position of the offending token.
+func (rpn *RPN) Next() (token.Token, error) {
+	if !rpn.recovering {
+		return rpn.next()
+	}
+
+	for !rpn.done() {
+		tok, err := rpn.next()
+		if errors.Is(err, io.EOF) {
+			if rpn.depth == 0 && len(rpn.errs) == 0 && !rpn.script {
+				rpn.report(&syntax.SyntaxError{
+					Kind:     syntax.MissingOperand,
+					Span:     rpn.span(rpn.end, rpn.end),
+					Expected: operandTokens,
+				})
+			}
+			break
+		}
+
+		if err != nil {
+			rpn.report(err)
+
+			var scanErr *syntax.ScanError
+			if errors.As(err, &scanErr) && rpn.operand {
+				// Let the invalid input stand in for the missing operand in order not to report it twice.
+				rpn.operand = false
+				rpn.depth++
+			}
+			continue
+		}
+
+		rpn.end = max(rpn.end, tok.End())
+
+		// Track the number of operands on the stack, assuming a missing operand has been replaced.
+		switch {
+		case tok.Type == token.Number || tok.Type == token.Ident:
+			rpn.depth++
+		case tok.Type == token.Call:
+			if rpn.depth < tok.Arity {
+				rpn.report(rpn.missingOperand(tok))
+				rpn.depth = tok.Arity
+			}
+			rpn.depth += 1 - tok.Arity
+		case token.IsOperator(tok):
+			if rpn.depth < 2 {
+				rpn.report(rpn.missingOperand(tok))
+				rpn.depth = 2
+			}
+			rpn.depth--
+		case tok.Type == token.Semicolon:
+			rpn.depth = 0
+		case token.IsUnary(tok) || tok.Type == token.Assign:
+			if rpn.depth < 1 {
+				rpn.report(rpn.missingOperand(tok))
+				rpn.depth = 1
+			}
+		}
+
+		return tok, nil
+	}
+
+	return token.Token{}, io.EOF
+}
+
+// report records err unless the maximum number of errors has been reached.
+func (rpn *RPN) report(err error) {
+	if !rpn.done() {
+		rpn.errs = append(rpn.errs, err)
+	}
+}
+
+// done returns whether the maximum number of errors has been recorded.
+func (rpn *RPN) done() bool { return rpn.max > 0 && len(rpn.errs) >= rpn.max }
+
+// next implements Next without recovering from errors.
+func (rpn *RPN) next() (token.Token, error) {
+	// Tokens which are not yielded right away continue the loop rather than recursing, so the stack does not
+	// grow with the number of parenthesis or signs in a row.
+	for {
+		if !rpn.out.Empty() {
+			return rpn.out.Shift(), nil
+		}
+
+		tok, err := rpn.scan()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return tok, err
+		}
+
+		if err != nil || (rpn.script && tok.Type == token.Semicolon) {
+			// The end of input or of a statement flushes the pending operators one at a time, so tok is read again
+			// until none are left.
+			if !rpn.operators.Empty() {
+				rpn.ahead, rpn.aheadTok, rpn.aheadErr = true, tok, err
+
+				op := rpn.operators.Pop()
+				if op.Type == token.LParen {
+					if rpn.groups.Pop() >= 0 {
+						// Drop the unfinished call.
+						rpn.operators.Pop()
+					}
+					found := ""
+					if err == nil {
+						found = tok.String()
+					}
+					return token.Token{}, &syntax.SyntaxError{
+						Kind:     syntax.UnclosedParen,
+						Span:     rpn.tokenSpan(tok),
+						Found:    found,
+						Expected: []string{")"},
+						Opening:  rpn.tokenSpan(op),
+					}
+				}
+				return op, nil
+			}
+
+			if rpn.script && rpn.prev != 0 && rpn.prev != token.Semicolon {
+				// Terminate the statement, unless it is empty.
+				rpn.prev = token.Semicolon
+				rpn.operand = true
+				return token.Token{Type: token.Semicolon, Literal: tok.Literal, Offset: tok.Offset}, nil
+			}
+
+			if err != nil {
+				return token.Token{}, io.EOF
+			}
+			continue
+		}
+
+		if !rpn.operand && (tok.Type == token.Number || tok.Type == token.Ident || tok.Type == token.LParen) {
+			// An operator is missing between two operands.
+			return token.Token{}, rpn.unexpectedOperand(tok)
+		}
+
+		prev := rpn.prev
+		rpn.prev = tok.Type
+
+		if tok.Type == token.Number {
+			rpn.operand = false
+			return tok, nil
+		}
+
+		if tok.Type == token.Ident {
+			ahead, err := rpn.s.Next()
+			if err == nil && ahead.Type == token.LParen {
+				tok.Type = token.Call
+				rpn.operators.Push(tok)
+				rpn.operators.Push(ahead)
+				rpn.groups.Push(0)
+				rpn.prev = token.LParen
+				rpn.operand = true
+				continue
+			}
+
+			if err == nil && ahead.Type == token.Assign && rpn.script && (prev == 0 || prev == token.Semicolon) {
+				// Assignments bind looser than any operator, so the Assign token follows the whole expression.
+				tok.Type = token.Assign
+				rpn.operators.Push(tok)
+				rpn.prev = token.Assign
+				rpn.operand = true
+				continue
+			}
+
+			rpn.ahead, rpn.aheadTok, rpn.aheadErr = true, ahead, err
+			rpn.operand = false
+			return tok, nil
+		}
+
+		if tok.Type == token.LParen {
+			rpn.operators.Push(tok)
+			rpn.groups.Push(-1)
+			continue
+		}
+
+		if tok.Type == token.Comma {
+			if rpn.groups.Empty() || rpn.groups.Peek() < 0 {
+				return token.Token{}, &syntax.SyntaxError{
+					Kind:     syntax.UnexpectedToken,
+					Span:     rpn.tokenSpan(tok),
+					Found:    tok.String(),
+					Expected: []string{"+", "-", "*", "/", "^"},
+				}
+			}
+
+			for rpn.operators.Peek().Type != token.LParen {
+				rpn.out.Push(rpn.operators.Pop())
+			}
+			rpn.groups[len(rpn.groups)-1]++
+			rpn.operand = true
+			continue
+		}
+
+		if rpn.operand && (tok.Type == token.Add || tok.Type == token.Sub) {
+			// Prefix signs bind tighter than any binary operator and are right associative, so pushing them never
+			// pops another operator.
+			if tok.Type == token.Add {
+				tok.Type = token.Plus
+			} else {
+				tok.Type = token.Neg
+			}
+			rpn.operators.Push(tok)
+			continue
+		}
+
+		if tok.Type == token.RParen {
+			rpn.operand = false
+			closing := tok
+			for {
+				if rpn.operators.Empty() {
+					return token.Token{}, &syntax.SyntaxError{
+						Kind:     syntax.UnexpectedToken,
+						Span:     rpn.tokenSpan(closing),
+						Found:    closing.String(),
+						Expected: []string{"+", "-", "*", "/", "^"},
+					}
+				}
+
+				tok = rpn.operators.Pop()
+				if tok.Type == token.LParen {
+					break
+				}
+
+				rpn.out.Push(tok)
+			}
+
+			if commas := rpn.groups.Pop(); commas >= 0 {
+				call := rpn.operators.Pop()
+				call.Arity = commas + 1
+				if commas == 0 && prev == token.LParen {
+					call.Arity = 0
+				}
+				rpn.out.Push(call)
+			}
+
+			continue
+		}
+
+		if token.IsOperator(tok) {
+			for !rpn.operators.Empty() {
+				top := rpn.operators.Peek()
+				if precedence(top) < precedence(tok) || top.Type == token.LParen {
+					break
+				}
+				// Pow is right associative, so an operator of equal precedence is kept on the stack.
+				if precedence(top) == precedence(tok) && tok.Type == token.Pow {
+					break
+				}
+				rpn.out.Push(rpn.operators.Pop())
+			}
+
+			rpn.operators.Push(tok)
+			rpn.operand = true
+			continue
+		}
+
+		// tok is an assignment that does not start a statement or a semicolon outside of script mode.
+		expected := []string{"+", "-", "*", "/", "^"}
+		if rpn.operand {
+			expected = operandTokens
+		}
+		return token.Token{}, &syntax.SyntaxError{
+			Kind:     syntax.UnexpectedToken,
+			Span:     rpn.tokenSpan(tok),
+			Found:    tok.String(),
+			Expected: expected,
+		}
+	}
+}
+
+// unexpectedOperand returns the error to report for tok, which starts an operand following another operand,
+// and skips the rest of that operand: the arguments of a call or a parenthesized expression.
+func (rpn *RPN) unexpectedOperand(tok token.Token) error {
+	err := &syntax.SyntaxError{
+		Kind:     syntax.UnexpectedToken,
+		Span:     rpn.tokenSpan(tok),
+		Found:    tok.String(),
+		Expected: rpn.expectedOperator(),
+	}
+
+	if tok.Type == token.Ident {
+		next, nextErr := rpn.scan()
+		if nextErr != nil || next.Type != token.LParen {
+			rpn.ahead, rpn.aheadTok, rpn.aheadErr = true, next, nextErr
+			return err
+		}
+	}
+	if tok.Type == token.Ident || tok.Type == token.LParen {
+		rpn.skipGroup()
+	}
+	return err
+}
+
+// skipGroup skips tokens up to and including the closing parenthesis matching an opening one read last. It
+// stops at the end of input and, in script mode, at the end of the statement.
+func (rpn *RPN) skipGroup() {
+	for depth := 1; depth > 0; {
+		tok, err := rpn.scan()
+		if errors.Is(err, io.EOF) || (rpn.script && tok.Type == token.Semicolon) {
+			rpn.ahead, rpn.aheadTok, rpn.aheadErr = true, tok, err
+			return
+		}
+		switch {
+		case err != nil:
+			if rpn.recovering {
+				rpn.report(err)
+			}
+		case tok.Type == token.LParen:
+			depth++
+		case tok.Type == token.RParen:
+			depth--
+		}
+	}
+}
+
+// expectedOperator returns the tokens that may follow an operand.
+func (rpn *RPN) expectedOperator() []string {
+	expected := []string{"+", "-", "*", "/", "^", ")", ","}
+	switch {
+	case rpn.groups.Empty():
+		return expected[:5]
+	case rpn.groups.Peek() < 0:
+		return expected[:6]
+	default:
+		return expected
+	}
+}
+
+// span returns the span from start to end.
+func (rpn *RPN) span(start, end int) token.Span {
+	return token.Span{Start: rpn.s.Pos(start), End: rpn.s.Pos(end)}
+}
+
+// tokenSpan returns the span of tok.
+func (rpn *RPN) tokenSpan(tok token.Token) token.Span {
+	return rpn.span(tok.Offset, tok.End())
+}
+
+// scan reads the next token from the scanner unless it has been read ahead already.
+func (rpn *RPN) scan() (token.Token, error) {
+	if rpn.ahead {
+		rpn.ahead = false
+		return rpn.aheadTok, rpn.aheadErr
+	}
+	return rpn.s.Next()
+}
+
+func precedence(t token.Token) int {
+	switch t.Type {
+	case token.Add, token.Sub:
+		return 1
+	case token.Mul, token.Div:
+		return 2
+	case token.Neg, token.Plus:
+		return 3
+	case token.Pow:
+		return 4
+	case token.LParen, token.RParen:
+		return 5
+	default:
+		return 0
+	}
+}
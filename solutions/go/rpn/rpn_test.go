@@ -0,0 +1,216 @@
+package rpn
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/halimath/calc/syntax"
+	"github.com/halimath/calc/token"
+	"github.com/halimath/expect"
+	"github.com/halimath/expect/is"
+)
+
+func TestRPN(t *testing.T) {
+	type testCase struct {
+		in   string
+		want []token.Token
+		err  error
+	}
+
+	tests := []testCase{
+		{in: "2+3", want: tokenize("2 3 +")},
+		{in: "2+3+4", want: tokenize("2 3 + 4 +")},
+		{in: "2+3*4", want: tokenize("2 3 4 * +")},
+		{in: "2+3*4-5", want: tokenize("2 3 4 * + 5 -")},
+		{in: "2+3*(4-5)", want: tokenize("2 3 4 5 - * +")},
+
+		{in: "-3", want: []token.Token{num("3"), neg}},
+		{in: "+3", want: []token.Token{num("3"), plus}},
+		{in: "- -4", want: []token.Token{num("4"), neg, neg}},
+		{in: "-2*3", want: []token.Token{num("2"), neg, num("3"), mul}},
+		{in: "2*-3", want: []token.Token{num("2"), num("3"), neg, mul}},
+		{in: "2--3", want: []token.Token{num("2"), num("3"), neg, sub}},
+		{in: "-(1+2)", want: []token.Token{num("1"), num("2"), add, neg}},
+
+		{in: "2^3^2", want: tokenize("2 3 2 ^ ^")},
+		{in: "(2^3)^2", want: tokenize("2 3 ^ 2 ^")},
+		{in: "2*3^2", want: tokenize("2 3 2 ^ *")},
+		{in: "3^2*2", want: tokenize("3 2 ^ 2 *")},
+		{in: "-2^2", want: []token.Token{num("2"), num("2"), pow, neg}},
+		{in: "2^-2", want: []token.Token{num("2"), num("2"), neg, pow}},
+
+		{in: "x * 2", want: []token.Token{ident("x"), num("2"), mul}},
+		{in: "sqrt(4)", want: []token.Token{num("4"), call("sqrt", 1)}},
+		{in: "max(1, 2 + x, 3) * 2", want: []token.Token{num("1"), num("2"), ident("x"), add, num("3"), call("max", 3), num("2"), mul}},
+		{in: "-abs(-2)^2", want: []token.Token{num("2"), neg, call("abs", 1), num("2"), pow, neg}},
+		{in: "min(max(1, 2), (3))", want: []token.Token{num("1"), num("2"), call("max", 2), num("3"), call("min", 2)}},
+		{in: "f() + 1", want: []token.Token{call("f", 0), num("1"), add}},
+		{in: "f(1,)", want: []token.Token{num("1"), call("f", 2)}},
+		{in: "(1, 2)", want: []token.Token{num("1")}, err: syntax.ErrInvalidSyntax},
+	}
+
+	for _, test := range tests {
+		r := New(syntax.NewScanner(strings.NewReader(test.in)))
+		got, err := consumeAll(r)
+
+		expect.WithMessage(t, "in: %q", test.in).That(
+			is.Error(err, test.err),
+			is.DeepEqualTo(withoutOffsets(got), test.want),
+		)
+	}
+}
+
+var (
+	add  = token.Token{Type: token.Add}
+	sub  = token.Token{Type: token.Sub}
+	mul  = token.Token{Type: token.Mul}
+	neg  = token.Token{Type: token.Neg}
+	plus = token.Token{Type: token.Plus}
+	pow  = token.Token{Type: token.Pow}
+
+	semicolon = token.Token{Type: token.Semicolon}
+)
+
+func ident(name string) token.Token {
+	return token.Token{Type: token.Ident, Literal: name}
+}
+
+func call(name string, arity int) token.Token {
+	return token.Token{Type: token.Call, Literal: name, Arity: arity}
+}
+
+func num(lit string) token.Token {
+	v, err := strconv.ParseFloat(lit, 64)
+	if err != nil {
+		panic(err)
+	}
+	return token.Token{Type: token.Number, Value: v, Literal: lit}
+}
+
+func TestRPN_Script(t *testing.T) {
+	type testCase struct {
+		in   string
+		want []token.Token
+		err  error
+	}
+
+	tests := []testCase{
+		{in: ""},
+		{in: ";;"},
+		{in: "1 + 2", want: []token.Token{num("1"), num("2"), add, semicolon}},
+		{in: "x = 1 + 2; x * 2;", want: []token.Token{num("1"), num("2"), add, assign("x"), semicolon, ident("x"), num("2"), mul, semicolon}},
+		{in: "x = -(1)\ny", want: []token.Token{num("1"), neg, assign("x"), {Type: token.Semicolon, Literal: "\n"}, ident("y"), semicolon}},
+		{in: "x = (1; 2", want: []token.Token{num("1")}, err: syntax.ErrInvalidSyntax},
+		{in: "1 + x = 2", want: []token.Token{num("1"), ident("x")}, err: syntax.ErrInvalidSyntax},
+	}
+
+	for _, test := range tests {
+		s := syntax.NewScanner(strings.NewReader(test.in))
+		s.Script()
+		r := New(s)
+		r.Script()
+		got, err := consumeAll(r)
+
+		expect.WithMessage(t, "in: %q", test.in).That(
+			is.Error(err, test.err),
+			is.DeepEqualTo(withoutOffsets(got), test.want),
+		)
+	}
+}
+
+func assign(name string) token.Token {
+	return token.Token{Type: token.Assign, Literal: name}
+}
+
+func TestRPN_errorPositions(t *testing.T) {
+	type testCase struct {
+		in   string
+		want string
+	}
+
+	tests := []testCase{
+		{in: "2 +\n  3)", want: "2:4: invalid syntax: unexpected \")\""},
+		{in: "1 + (2 * (3)", want: "1:13: invalid syntax: expected ) but got end of input (unclosed ( at 1:5)"},
+		{in: "1 + #", want: "1:5: scan failed: invalid input rune: #"},
+		{in: "max(1,\n  2", want: "2:4: invalid syntax: expected ) but got end of input (unclosed ( at 1:4)"},
+		{in: "1 + (2, 3)", want: "1:7: invalid syntax: unexpected \",\""},
+		{in: "1; 2", want: "1:2: invalid syntax: unexpected \";\""},
+	}
+
+	for _, test := range tests {
+		_, err := consumeAll(New(syntax.NewScanner(strings.NewReader(test.in))))
+
+		expect.WithMessage(t, "in: %q", test.in).That(
+			is.EqualTo(err.Error(), test.want),
+		)
+	}
+}
+
+func TestRPN_Recover(t *testing.T) {
+	r := New(syntax.NewScanner(strings.NewReader("2 + #) * 3 + (4")))
+	r.Recover(0)
+	got, err := consumeAll(r)
+
+	var msgs []string
+	for _, e := range r.Errors() {
+		msgs = append(msgs, e.Error())
+	}
+
+	expect.That(t,
+		is.NoError(err),
+		is.DeepEqualTo(withoutOffsets(got), tokenize("2 + 3 * 4 +")),
+		is.DeepEqualTo(msgs, []string{
+			"1:5: scan failed: invalid input rune: #",
+			"1:6: invalid syntax: unexpected \")\"",
+			"1:16: invalid syntax: expected ) but got end of input (unclosed ( at 1:14)",
+		}),
+	)
+}
+
+func consumeAll(l *RPN) (toks []token.Token, err error) {
+	var t token.Token
+	for {
+		t, err = l.Next()
+		if errors.Is(err, io.EOF) {
+			err = nil
+			return
+		}
+
+		if err != nil {
+			return
+		}
+
+		toks = append(toks, t)
+	}
+}
+
+// withoutOffsets returns toks with the offsets set to 0, so that tokens are compared regardless of their
+// location.
+func withoutOffsets(toks []token.Token) []token.Token {
+	for i := range toks {
+		toks[i].Offset = 0
+	}
+	return toks
+}
+
+// tokenize returns the tokens of s without their offsets.
+func tokenize(s string) (toks []token.Token) {
+	l := syntax.NewScanner(strings.NewReader(s))
+
+	for {
+		t, err := l.Next()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+
+		if err != nil {
+			panic(err)
+		}
+
+		t.Offset = 0
+		toks = append(toks, t)
+	}
+}
@@ -34,6 +34,9 @@ func (s *Stack[T]) Pop() T {
 }
 
 // Shift removes the bottom-most element from s and returns it. It panics if s is empty.
+//
+// Deprecated: Shift reslices s, so s keeps the memory of shifted elements until it grows. Use a queue.Queue
+// instead.
 func (s *Stack[T]) Shift() T {
 	if len(*s) == 0 {
 		panic("empty stack")
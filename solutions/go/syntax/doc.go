@@ -0,0 +1,10 @@
+// Package syntax implements the front end of the calculator: a Scanner splitting input into token.Token
+// values and a Parser building an ast.Node tree from them.
+//
+//	p := syntax.NewParser(syntax.NewScanner(r))
+//	root, err := p.Expr()
+//
+// Errors are reported as *ScanError and *SyntaxError values, which describe the offending span of input. The
+// converter of package rpn reports the same types. Both the Scanner and the Parser stop at the first error by
+// default; see Scanner.Strict and Parser.Recover for the other modes of operation.
+package syntax
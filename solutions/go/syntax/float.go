@@ -0,0 +1,220 @@
+package syntax
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"math/bits"
+	"strconv"
+)
+
+// parseFloat converts the decimal number b, which consists of digits and an optional decimal point, to the
+// nearest float64. The result is exactly the one strconv.ParseFloat returns for the same input, including
+// any error.
+//
+// parseFloat uses the exact conversion of small numbers described by Clinger and the Eisel-Lemire algorithm
+// for all others. It falls back to strconv.ParseFloat for input neither of them handles, such as numbers
+// outside of the range of normal float64 values and malformed input.
+func parseFloat(b []byte) (float64, error) {
+	if f, ok := parseDecimal(b); ok {
+		return f, nil
+	}
+	// Converting b does not allocate as ParseFloat does not retain it.
+	return strconv.ParseFloat(string(b), 64)
+}
+
+// maxMantissaDigits is the number of decimal digits that always fit into an uint64.
+const maxMantissaDigits = 19
+
+// parseDecimal converts b to a float64 if b consists of ASCII digits with at most one decimal point and the
+// result is found to be correctly rounded.
+func parseDecimal(b []byte) (float64, bool) {
+	var (
+		man    uint64
+		digits int
+		// exp10 is the power of ten to multiply man with.
+		exp10 int
+		// dot and any are true once a decimal point or a digit has been read.
+		dot, any bool
+		// truncated is true if a non-zero digit did not fit into man.
+		truncated bool
+	)
+
+	for _, c := range b {
+		if c == '.' {
+			if dot {
+				return 0, false
+			}
+			dot = true
+			continue
+		}
+
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		any = true
+
+		switch {
+		case c == '0' && digits == 0:
+			// Leading zeros are not significant.
+			if dot {
+				exp10--
+			}
+		case digits < maxMantissaDigits:
+			man = man*10 + uint64(c-'0')
+			digits++
+			if dot {
+				exp10--
+			}
+		default:
+			truncated = truncated || c != '0'
+			if !dot {
+				exp10++
+			}
+		}
+	}
+
+	if !any {
+		return 0, false
+	}
+	if man == 0 {
+		return 0, true
+	}
+
+	if !truncated {
+		return convert(man, exp10)
+	}
+
+	// The digits dropped lie between man and man+1, so the result is known if both round to the same float64.
+	f, ok := convert(man, exp10)
+	if !ok {
+		return 0, false
+	}
+	g, ok := convert(man+1, exp10)
+	if !ok || f != g {
+		return 0, false
+	}
+	return f, true
+}
+
+// exactPowersOfTen contains the powers of ten exactly representable as float64.
+var exactPowersOfTen = [...]float64{
+	1e0, 1e1, 1e2, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9, 1e10, 1e11,
+	1e12, 1e13, 1e14, 1e15, 1e16, 1e17, 1e18, 1e19, 1e20, 1e21, 1e22,
+}
+
+// convert returns the float64 nearest to man * 10^exp10 for man > 0. It reports false if the result cannot be
+// determined without resorting to arbitrary precision.
+func convert(man uint64, exp10 int) (float64, bool) {
+	// Both man and the power of ten are exact, so a single multiplication or division rounds correctly.
+	if man <= 1<<53 && -len(exactPowersOfTen) < exp10 && exp10 < len(exactPowersOfTen) {
+		if exp10 < 0 {
+			return float64(man) / exactPowersOfTen[-exp10], true
+		}
+		return float64(man) * exactPowersOfTen[exp10], true
+	}
+	return eiselLemire(man, exp10)
+}
+
+// eiselLemire implements the algorithm by Michael Eisel and Daniel Lemire, which multiplies man with a 128
+// bit approximation of 10^exp10 and reports false if the approximation leaves the rounding undecided. It
+// does not handle results outside of the range of normal float64 values either.
+//
+// See Daniel Lemire, Number Parsing at a Gigabyte per Second, Software: Practice and Experience 51 (8), 2021.
+func eiselLemire(man uint64, exp10 int) (float64, bool) {
+	if exp10 < minPowerOfTen || exp10 > maxPowerOfTen {
+		return 0, false
+	}
+	pow := powersOfTen[exp10-minPowerOfTen]
+
+	clz := bits.LeadingZeros64(man)
+	man <<= clz
+	// 217706 / 2^16 approximates log2(10), so exp2 is the biased exponent of the result or one more.
+	exp2 := uint64(217706*exp10>>16+64+1023) - uint64(clz)
+
+	hi, lo := bits.Mul64(man, pow.hi)
+	if hi&0x1ff == 0x1ff && lo+man < man {
+		// The lower 9 bits of hi may be off, so take the lower half of the power of ten into account.
+		yhi, ylo := bits.Mul64(man, pow.lo)
+		mhi, mlo := hi, lo+yhi
+		if mlo < lo {
+			mhi++
+		}
+		if mhi&0x1ff == 0x1ff && mlo+1 == 0 && ylo+man < man {
+			return 0, false
+		}
+		hi, lo = mhi, mlo
+	}
+
+	msb := hi >> 63
+	mantissa := hi >> (msb + 9)
+	exp2 -= 1 ^ msb
+
+	// The product might lie exactly halfway between two float64 values, which requires rounding to even.
+	if lo == 0 && hi&0x1ff == 0 && mantissa&3 == 1 {
+		return 0, false
+	}
+
+	mantissa += mantissa & 1
+	mantissa >>= 1
+	if mantissa>>53 > 0 {
+		mantissa >>= 1
+		exp2++
+	}
+
+	// Subnormal numbers and overflows are left to strconv.
+	if exp2-1 >= 0x7ff-1 {
+		return 0, false
+	}
+	return math.Float64frombits(exp2<<52 | mantissa&(1<<52-1)), true
+}
+
+// The range of the powers of ten eiselLemire supports. Any float64 other than 0 is at least 10^-324 and
+// less than 10^309, while the mantissa has up to 19 digits.
+const (
+	minPowerOfTen = -348
+	maxPowerOfTen = 347
+)
+
+// uint128 is an unsigned 128 bit integer.
+type uint128 struct{ hi, lo uint64 }
+
+// powersOfTen contains the 128 most significant bits of the powers of ten from 10^minPowerOfTen to
+// 10^maxPowerOfTen, which are computed when initializing the package.
+var powersOfTen = computePowersOfTen()
+
+// computePowersOfTen computes powersOfTen. As 10^q = 5^q * 2^q, the bits of 10^q are those of 5^q. They are
+// truncated for q >= 0 and rounded up for small negative q, following the reference implementation.
+func computePowersOfTen() []uint128 {
+	powers := make([]uint128, maxPowerOfTen-minPowerOfTen+1)
+	one := big.NewInt(1)
+	five := big.NewInt(5)
+
+	var p, c big.Int
+	for q := minPowerOfTen; q <= maxPowerOfTen; q++ {
+		if q < 0 {
+			p.Exp(five, big.NewInt(int64(-q)), nil)
+			z := p.BitLen()
+			b := 2*z + 128
+			if q >= -27 {
+				b = z + 127
+			}
+			c.Div(c.Lsh(one, uint(b)), &p)
+			c.Add(&c, one)
+		} else {
+			c.Exp(five, big.NewInt(int64(q)), nil)
+		}
+
+		if n := c.BitLen(); n > 128 {
+			c.Rsh(&c, uint(n-128))
+		} else {
+			c.Lsh(&c, uint(128-n))
+		}
+
+		var words [16]byte
+		c.FillBytes(words[:])
+		powers[q-minPowerOfTen] = uint128{hi: binary.BigEndian.Uint64(words[:8]), lo: binary.BigEndian.Uint64(words[8:])}
+	}
+
+	return powers
+}
@@ -0,0 +1,106 @@
+package syntax
+
+import (
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/halimath/expect"
+	"github.com/halimath/expect/is"
+)
+
+func TestParseFloat(t *testing.T) {
+	tests := []string{
+		"0", "000", "0.0", ".5", "1.", "1", "12.75", "0.1", "0.3", "807.1328", "3.14159265358979323846",
+		// Largest integer exactly representable and the halfway cases around it.
+		"9007199254740992", "9007199254740993", "9007199254740994", "9007199254740995",
+		"18446744073709551615", "18446744073709551616", "123456789012345678901234567890",
+		// Halfway between 1 and the next float64, followed by digits beyond the 19 kept in the mantissa.
+		"1.00000000000000011102230246251565404236316680908203125",
+		"1.00000000000000011102230246251565404236316680908203124",
+		"1.00000000000000011102230246251565404236316680908203126",
+		"0." + strings.Repeat("0", 307) + "22250738585072014",
+		"0." + strings.Repeat("0", 307) + "22250738585072011",
+		"0." + strings.Repeat("0", 323) + "4940656458412465441765687928682213723651",
+		"0." + strings.Repeat("0", 330) + "1",
+		"17976931348623157" + strings.Repeat("0", 292),
+		"17976931348623159" + strings.Repeat("0", 292),
+		"1" + strings.Repeat("0", 400),
+		"1" + strings.Repeat("0", 30) + "." + strings.Repeat("0", 30) + "1",
+		"", ".", "..", "1.2.3", "1a", "١٢",
+	}
+
+	for _, test := range tests {
+		want, wantErr := strconv.ParseFloat(test, 64)
+		got, err := parseFloat([]byte(test))
+
+		expect.That(t,
+			is.EqualTo(math.Float64bits(got), math.Float64bits(want)),
+			is.DeepEqualTo(err, wantErr),
+		)
+	}
+}
+
+func TestParseFloat_random(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < 100_000; i++ {
+		var b strings.Builder
+		for range r.IntN(25) + 1 {
+			b.WriteByte(byte('0' + r.IntN(10)))
+		}
+		if r.IntN(2) == 0 {
+			b.WriteByte('.')
+			for range r.IntN(25) {
+				b.WriteByte(byte('0' + r.IntN(10)))
+			}
+		}
+
+		testParseFloat(t, b.String())
+	}
+}
+
+func FuzzParseFloat(f *testing.F) {
+	for _, seed := range []string{"0", "1", "0.1", "12.75", "9007199254740993", "123456789012345678901234567890.5", "1.2.3"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(testParseFloat)
+}
+
+// testParseFloat tests parseFloat to return the same as strconv.ParseFloat for s.
+func testParseFloat(t *testing.T, s string) {
+	t.Helper()
+
+	want, wantErr := strconv.ParseFloat(s, 64)
+	got, err := parseFloat([]byte(s))
+
+	if math.Float64bits(got) != math.Float64bits(want) || (err == nil) != (wantErr == nil) {
+		t.Fatalf("parseFloat(%q) = %v, %v; strconv.ParseFloat returns %v, %v", s, got, err, want, wantErr)
+	}
+}
+
+func BenchmarkParseFloat(b *testing.B) {
+	numbers := [][]byte{[]byte("17"), []byte("807.1328"), []byte("0.123"), []byte("3.14159265358979323846")}
+
+	b.Run("parseFloat", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			for _, num := range numbers {
+				if _, err := parseFloat(num); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("strconv", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			for _, num := range numbers {
+				if _, err := strconv.ParseFloat(string(num), 64); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,498 @@
+package syntax
+
+import (
+	"errors"
+	"io"
+	"slices"
+
+	"github.com/halimath/calc/ast"
+	"github.com/halimath/calc/token"
+)
+
+// ErrInvalidSyntax is wrapped by all syntax errors.
+var ErrInvalidSyntax = errors.New("invalid syntax")
+
+var (
+	// operandTokens lists the tokens that may start an operand.
+	operandTokens = []string{"number", "identifier", "(", "+", "-"}
+	// operatorTokens lists the tokens that may follow an operand. The closing parenthesis is only valid inside
+	// parenthesis and the comma only inside the argument list of a call.
+	operatorTokens = []string{"+", "-", "*", "/", "^", ")", ","}
+)
+
+// Parser implements parsing the tokens produced by a Scanner into an abstract syntax tree.
+type Parser struct {
+	s *Scanner
+	// current is the current token. Its Type is 0 at the end of input.
+	current token.Token
+
+	// skipped is true if invalid input has been skipped right before current.
+	skipped bool
+
+	// back is true if a token has been put back using unread. backTok and backSkipped are the values current
+	// and skipped take when advancing the next time.
+	back        bool
+	backTok     token.Token
+	backSkipped bool
+
+	// stmt is true while parsing a script, in which a Semicolon terminates an expression.
+	stmt bool
+
+	// recovering enables recovery mode, in which up to max errors (or any number if max < 1) are collected in
+	// errs. Otherwise, parsing stops at the first error.
+	recovering bool
+	max        int
+	errs       ErrorList
+}
+
+// NewParser creates a new Parser consuming tokens from s.
+func NewParser(s *Scanner) *Parser {
+	return &Parser{s: s}
+}
+
+// Recover switches p to recovery mode. Instead of stopping at the first error, Expr skips offending tokens up
+// to the next operator or parenthesis and continues parsing. It reports all errors found - at most max of
+// them unless max is less than 1 - as an ErrorList along with a partial tree, in which operands that could
+// not be parsed are replaced by ast.Invalid.
+func (p *Parser) Recover(max int) {
+	p.recovering = true
+	p.max = max
+}
+
+// Expr parses an expression from the token stream. Chained operators of the same precedence are
+// combined left-associative, i.e. 10 - 4 - 3 is parsed as (10 - 4) - 3. An identifier followed by an opening
+// parenthesis is parsed as an ast.Call, i.e. max(1, 2).
+//
+// Expr does not recurse. Pending operators and operands are kept on heap allocated stacks, so the parser
+// handles arbitrarily long as well as deeply nested expressions without growing the goroutine's stack.
+//
+// Expr stops at the first error unless p is in recovery mode (see Recover). All errors returned by Expr are
+// prefixed with the position of the offending token.
+func (p *Parser) Expr() (ast.Node, error) {
+	p.advance()
+	root := p.expr()
+
+	if len(p.errs) > 0 {
+		if !p.recovering {
+			return nil, p.errs[0]
+		}
+		return root, p.errs
+	}
+
+	return root, nil
+}
+
+// Program parses a script: a sequence of statements separated by semicolons or - if the scanner is in script
+// mode, see Scanner.Script - line breaks. A statement is either an assignment of the form name = expr, which
+// is parsed as an ast.Assign, or an expression. Empty statements are ignored.
+//
+// Errors are reported the same way as by Expr. In recovery mode, the partial program contains every statement
+// that has been parsed.
+func (p *Parser) Program() (*ast.Program, error) {
+	p.stmt = true
+	var prog ast.Program
+
+	p.advance()
+	for p.current.Type != 0 && !p.done() {
+		if p.current.Type == token.Semicolon {
+			p.advance()
+			continue
+		}
+		prog.Stmts = append(prog.Stmts, p.statement())
+	}
+
+	if len(p.errs) > 0 {
+		if !p.recovering {
+			return nil, p.errs[0]
+		}
+		return &prog, p.errs
+	}
+
+	return &prog, nil
+}
+
+// statement parses a statement of a script starting at the current token.
+func (p *Parser) statement() ast.Node {
+	if p.current.Type == token.Ident {
+		name, skipped := p.current, p.skipped
+		p.advance()
+		if p.current.Type == token.Assign && !p.skipped {
+			p.advance()
+			return ast.Assign{Name: name.Literal, X: p.expr(), Span: p.span(name)}
+		}
+		p.unread(name, skipped)
+	}
+
+	return p.expr()
+}
+
+// expr parses an expression starting at the current token up to the end of input or, while parsing a script,
+// the end of the statement. It records errors in p.errs and returns nil if it stopped at an error outside of
+// recovery mode.
+func (p *Parser) expr() ast.Node {
+	operands := make([]ast.Node, 0, 16)
+	operators := make([]token.Token, 0, 16)
+	// groups contains an entry for every open parenthesis: the number of operands preceding the arguments for
+	// the parenthesis of a call and -1 otherwise. Calls are kept on operators below their opening parenthesis.
+	groups := make([]int, 0, 16)
+
+	reduce := func() {
+		op := operators[len(operators)-1]
+		operators = operators[:len(operators)-1]
+
+		if token.IsUnary(op) {
+			operands[len(operands)-1] = ast.Unary{
+				X:    operands[len(operands)-1],
+				Op:   astOp(op.Type),
+				Span: p.span(op),
+			}
+			return
+		}
+
+		l, r := operands[len(operands)-2], operands[len(operands)-1]
+		operands = operands[:len(operands)-2]
+
+		operands = append(operands, ast.Operator{
+			L:    l,
+			R:    r,
+			Op:   astOp(op.Type),
+			Span: p.span(op),
+		})
+	}
+
+	// closeGroup removes the innermost opening parenthesis from the top of operators and completes the call it
+	// belongs to, if any.
+	closeGroup := func() {
+		operators = operators[:len(operators)-1]
+		base := groups[len(groups)-1]
+		groups = groups[:len(groups)-1]
+		if base < 0 {
+			return
+		}
+
+		fn := operators[len(operators)-1]
+		operators = operators[:len(operators)-1]
+
+		var args []ast.Node
+		if len(operands) > base {
+			args = slices.Clone(operands[base:])
+		}
+		operands = append(operands[:base], ast.Call{Name: fn.Literal, Args: args, Span: p.span(fn)})
+	}
+
+	// inCall returns whether the innermost open parenthesis encloses the arguments of a call.
+	inCall := func() bool {
+		return len(groups) > 0 && groups[len(groups)-1] >= 0
+	}
+
+	// expected returns the tokens that may follow an operand.
+	expected := func() []string {
+		switch {
+		case len(groups) == 0:
+			return operatorTokens[:len(operatorTokens)-2]
+		case !inCall():
+			return operatorTokens[:len(operatorTokens)-1]
+		default:
+			return operatorTokens
+		}
+	}
+
+	// needOperand is true while the parser expects an operand.
+	needOperand := true
+
+parse:
+	for !p.done() {
+		// Expect an operand: either a number, an identifier, an opening parenthesis or a prefix sign.
+		needOperand = true
+
+		if p.current.Type == token.LParen {
+			operators = append(operators, p.current)
+			groups = append(groups, -1)
+			p.advance()
+			continue
+		}
+
+		if p.current.Type == token.Add || p.current.Type == token.Sub {
+			// Prefix signs bind tighter than any binary operator, so they never cause a reduction.
+			sign := p.current
+			sign.Type = token.Plus
+			if p.current.Type == token.Sub {
+				sign.Type = token.Neg
+			}
+			operators = append(operators, sign)
+			p.advance()
+			continue
+		}
+
+		if p.current.Type == token.Number {
+			operands = append(operands, ast.Number{Value: p.current.Value, Literal: p.current.Literal, Span: p.span(p.current)})
+			p.advance()
+		} else if p.current.Type == token.Ident {
+			ident := p.current
+			p.advance()
+
+			if p.current.Type != token.LParen || p.skipped {
+				operands = append(operands, ast.Ident{Name: ident.Literal, Span: p.span(ident)})
+			} else {
+				operators = append(operators, token.Token{Type: token.Call, Literal: ident.Literal, Offset: ident.Offset}, p.current)
+				groups = append(groups, len(operands))
+				p.advance()
+
+				if p.current.Type != token.RParen {
+					continue
+				}
+				// The call has no arguments.
+				closeGroup()
+				p.advance()
+			}
+		} else {
+			// Unless the operand has been skipped as invalid input already, current is a binary operator, a
+			// closing parenthesis, a comma, an assignment, a semicolon or the end of input. Those valid after an
+			// operand are handled when expecting an operator, the others are skipped right away.
+			if !p.skipped {
+				p.report(p.unexpected(operandTokens))
+			}
+			if p.done() {
+				break
+			}
+			operands = append(operands, ast.Invalid{Span: p.span(p.current)})
+			switch {
+			case p.current.Type == token.RParen && len(groups) == 0,
+				p.current.Type == token.Comma && !inCall(),
+				p.current.Type == token.Assign,
+				p.current.Type == token.Semicolon && !p.stmt:
+				// Already reported as unexpected.
+				p.advance()
+			}
+		}
+		needOperand = false
+
+		// Expect an operator or a closing parenthesis. Closing parenthesis may follow each other.
+		for !p.done() {
+			if p.end() {
+				break parse
+			}
+
+			if p.current.Type == token.RParen {
+				if len(groups) == 0 {
+					p.report(p.unexpected(expected()))
+					p.advance()
+					continue
+				}
+
+				for operators[len(operators)-1].Type != token.LParen {
+					reduce()
+				}
+				closeGroup()
+				p.advance()
+				continue
+			}
+
+			if p.current.Type == token.Comma && inCall() {
+				// Complete the argument and expect the next one.
+				for operators[len(operators)-1].Type != token.LParen {
+					reduce()
+				}
+				p.advance()
+				needOperand = true
+				continue parse
+			}
+
+			op := p.current
+			if !token.IsOperator(op) {
+				// An operand or an opening parenthesis lacks an operator in front of it.
+				p.report(p.unexpected(expected()))
+				p.skip(inCall())
+				continue
+			}
+
+			// Reduce pending operators binding at least as tight as op. For the right associative Pow, operators
+			// of equal precedence stay on the stack, so 2 ^ 3 ^ 2 is parsed as 2 ^ (3 ^ 2).
+			for len(operators) > 0 && operators[len(operators)-1].Type != token.LParen {
+				top := precedence(operators[len(operators)-1].Type)
+				if top < precedence(op.Type) || (top == precedence(op.Type) && op.Type == token.Pow) {
+					break
+				}
+				reduce()
+			}
+			operators = append(operators, op)
+			// Advancing may hit the error limit, which ends parsing before the operand is read.
+			p.advance()
+			needOperand = true
+			continue parse
+		}
+	}
+
+	// Report every unclosed parenthesis, innermost first.
+	for i := len(operators) - 1; i >= 0 && !p.done(); i-- {
+		if operators[i].Type == token.LParen {
+			expected := operatorTokens[:len(operatorTokens)-1]
+			if i > 0 && operators[i-1].Type == token.Call {
+				expected = operatorTokens
+			}
+			p.report(&SyntaxError{
+				Kind:     UnclosedParen,
+				Span:     p.span(p.current),
+				Found:    p.found(),
+				Expected: expected,
+				Opening:  p.span(operators[i]),
+			})
+		}
+	}
+
+	if len(p.errs) > 0 && !p.recovering {
+		return nil
+	}
+
+	// Complete the partial tree if parsing stopped early.
+	if needOperand {
+		operands = append(operands, ast.Invalid{Span: p.span(p.current)})
+	}
+	for len(operators) > 0 {
+		if operators[len(operators)-1].Type == token.LParen {
+			closeGroup()
+			continue
+		}
+		reduce()
+	}
+
+	return operands[0]
+}
+
+// report records err unless the maximum number of errors has been reached.
+func (p *Parser) report(err error) {
+	if !p.done() {
+		p.errs = append(p.errs, err)
+	}
+}
+
+// done returns whether parsing has to stop because of the errors reported so far.
+func (p *Parser) done() bool {
+	if !p.recovering {
+		return len(p.errs) > 0
+	}
+	return p.max > 0 && len(p.errs) >= p.max
+}
+
+// skip discards tokens up to the next operator or a closing parenthesis, i.e. up to the next token that may
+// follow an operand. Parenthesized groups are discarded as a whole. Inside the argument list of a call, skip
+// stops at a comma as well.
+func (p *Parser) skip(call bool) {
+	nested := 0
+	for !p.end() && !p.done() {
+		switch p.current.Type {
+		case token.LParen:
+			nested++
+		case token.RParen:
+			if nested == 0 {
+				return
+			}
+			nested--
+		case token.Comma:
+			if call && nested == 0 {
+				return
+			}
+		default:
+			if token.IsOperator(p.current) && nested == 0 {
+				return
+			}
+		}
+		p.advance()
+	}
+}
+
+// end returns whether the current token ends the expression being parsed: the end of input or, while parsing
+// a script, a Semicolon.
+func (p *Parser) end() bool {
+	return p.current.Type == 0 || (p.stmt && p.current.Type == token.Semicolon)
+}
+
+// unexpected creates the error to report when the current token is not valid at its position.
+func (p *Parser) unexpected(expected []string) error {
+	return &SyntaxError{
+		Kind:     UnexpectedToken,
+		Span:     p.span(p.current),
+		Found:    p.found(),
+		Expected: expected,
+	}
+}
+
+// span returns the span of tok.
+func (p *Parser) span(tok token.Token) token.Span {
+	return p.s.span(tok.Offset, tok.End())
+}
+
+// found returns the current token as reported in errors, which is empty at the end of input.
+func (p *Parser) found() string {
+	if p.current.Type == 0 {
+		return ""
+	}
+	return p.current.String()
+}
+
+func precedence(op token.Type) int {
+	switch op {
+	case token.Add, token.Sub:
+		return 1
+	case token.Mul, token.Div:
+		return 2
+	case token.Neg, token.Plus:
+		return 3
+	case token.Pow:
+		return 4
+	default:
+		return 0
+	}
+}
+
+func astOp(op token.Type) ast.Op {
+	switch op {
+	case token.Add, token.Plus:
+		return ast.Add
+	case token.Sub, token.Neg:
+		return ast.Sub
+	case token.Mul:
+		return ast.Mul
+	case token.Div:
+		return ast.Div
+	default:
+		return ast.Pow
+	}
+}
+
+// advance moves to the next token. Scan errors are reported and the invalid input is skipped.
+func (p *Parser) advance() {
+	if p.back {
+		p.back = false
+		p.current, p.skipped = p.backTok, p.backSkipped
+		return
+	}
+
+	p.skipped = false
+
+	for {
+		var err error
+		p.current, err = p.s.Next()
+		if err == nil {
+			return
+		}
+
+		p.current = token.Token{Offset: p.current.Offset}
+		if errors.Is(err, io.EOF) || p.done() {
+			return
+		}
+
+		p.report(err)
+		p.skipped = true
+		if p.done() {
+			return
+		}
+	}
+}
+
+// unread puts back the current token, making tok and skipped the current state again. The current token becomes
+// current again when advancing the next time. unread must only be called once before advancing.
+func (p *Parser) unread(tok token.Token, skipped bool) {
+	p.back, p.backTok, p.backSkipped = true, p.current, p.skipped
+	p.current, p.skipped = tok, skipped
+}
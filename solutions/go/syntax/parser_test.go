@@ -0,0 +1,518 @@
+package syntax
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/halimath/calc/ast"
+	"github.com/halimath/calc/token"
+	"github.com/halimath/expect"
+	"github.com/halimath/expect/is"
+)
+
+func TestParser(t *testing.T) {
+	type testCase struct {
+		in   string
+		want ast.Node
+		err  error
+	}
+
+	tests := []testCase{
+		{in: "2", want: ast.Number{Value: 2, Literal: "2"}},
+		{in: "x", want: ast.Ident{Name: "x"}},
+		{in: "f()", want: ast.Call{Name: "f"}},
+		{in: "sqrt(2)", want: ast.Call{Name: "sqrt", Args: []ast.Node{ast.Number{Value: 2, Literal: "2"}}}},
+		{
+			in: "-max(1, x * 2, (3)) ^ 2", want: ast.Unary{
+				X: ast.Operator{
+					L: ast.Call{Name: "max", Args: []ast.Node{
+						ast.Number{Value: 1, Literal: "1"},
+						ast.Operator{L: ast.Ident{Name: "x"}, R: ast.Number{Value: 2, Literal: "2"}, Op: ast.Mul},
+						ast.Number{Value: 3, Literal: "3"},
+					}},
+					R:  ast.Number{Value: 2, Literal: "2"},
+					Op: ast.Pow,
+				},
+				Op: ast.Sub,
+			},
+		},
+		{
+			in: "min(abs(x), 1) + 1", want: ast.Operator{
+				L: ast.Call{Name: "min", Args: []ast.Node{
+					ast.Call{Name: "abs", Args: []ast.Node{ast.Ident{Name: "x"}}},
+					ast.Number{Value: 1, Literal: "1"},
+				}},
+				R:  ast.Number{Value: 1, Literal: "1"},
+				Op: ast.Add,
+			},
+		},
+		{in: "(1, 2)", err: ErrInvalidSyntax},
+		{in: "f(1,)", err: ErrInvalidSyntax},
+		{in: "f(,1)", err: ErrInvalidSyntax},
+		{
+			in: "2*rate", want: ast.Operator{
+				L:  ast.Number{Value: 2, Literal: "2"},
+				R:  ast.Ident{Name: "rate"},
+				Op: ast.Mul,
+			},
+		},
+		{
+			in: "2+3", want: ast.Operator{
+				L:  ast.Number{Value: 2, Literal: "2"},
+				R:  ast.Number{Value: 3, Literal: "3"},
+				Op: ast.Add,
+			},
+		},
+		{
+			in: "2-3", want: ast.Operator{
+				L:  ast.Number{Value: 2, Literal: "2"},
+				R:  ast.Number{Value: 3, Literal: "3"},
+				Op: ast.Sub,
+			},
+		},
+		{
+			in: "2+3-4", want: ast.Operator{
+				L: ast.Operator{
+					L:  ast.Number{Value: 2, Literal: "2"},
+					R:  ast.Number{Value: 3, Literal: "3"},
+					Op: ast.Add,
+				},
+				R:  ast.Number{Value: 4, Literal: "4"},
+				Op: ast.Sub,
+			},
+		},
+		{
+			in: "10-4-3", want: ast.Operator{
+				L: ast.Operator{
+					L:  ast.Number{Value: 10, Literal: "10"},
+					R:  ast.Number{Value: 4, Literal: "4"},
+					Op: ast.Sub,
+				},
+				R:  ast.Number{Value: 3, Literal: "3"},
+				Op: ast.Sub,
+			},
+		},
+		{
+			in: "2*3", want: ast.Operator{
+				L:  ast.Number{Value: 2, Literal: "2"},
+				R:  ast.Number{Value: 3, Literal: "3"},
+				Op: ast.Mul,
+			},
+		},
+		{
+			in: "2*3/4", want: ast.Operator{
+				L: ast.Operator{
+					L:  ast.Number{Value: 2, Literal: "2"},
+					R:  ast.Number{Value: 3, Literal: "3"},
+					Op: ast.Mul,
+				},
+				R:  ast.Number{Value: 4, Literal: "4"},
+				Op: ast.Div,
+			},
+		},
+		{
+			in: "8/4/2", want: ast.Operator{
+				L: ast.Operator{
+					L:  ast.Number{Value: 8, Literal: "8"},
+					R:  ast.Number{Value: 4, Literal: "4"},
+					Op: ast.Div,
+				},
+				R:  ast.Number{Value: 2, Literal: "2"},
+				Op: ast.Div,
+			},
+		},
+		{
+			in: "1-2*3-4", want: ast.Operator{
+				L: ast.Operator{
+					L: ast.Number{Value: 1, Literal: "1"},
+					R: ast.Operator{
+						L:  ast.Number{Value: 2, Literal: "2"},
+						R:  ast.Number{Value: 3, Literal: "3"},
+						Op: ast.Mul,
+					},
+					Op: ast.Sub,
+				},
+				R:  ast.Number{Value: 4, Literal: "4"},
+				Op: ast.Sub,
+			},
+		},
+		{
+			in: "2+3*4", want: ast.Operator{
+				L: ast.Number{Value: 2, Literal: "2"},
+				R: ast.Operator{
+					L:  ast.Number{Value: 3, Literal: "3"},
+					R:  ast.Number{Value: 4, Literal: "4"},
+					Op: ast.Mul,
+				},
+				Op: ast.Add,
+			},
+		},
+		{
+			in: "2*3+4", want: ast.Operator{
+				L: ast.Operator{
+					L:  ast.Number{Value: 2, Literal: "2"},
+					R:  ast.Number{Value: 3, Literal: "3"},
+					Op: ast.Mul,
+				},
+				R:  ast.Number{Value: 4, Literal: "4"},
+				Op: ast.Add,
+			},
+		},
+		{
+			in: "(2+3)*4", want: ast.Operator{
+				L: ast.Operator{
+					L:  ast.Number{Value: 2, Literal: "2"},
+					R:  ast.Number{Value: 3, Literal: "3"},
+					Op: ast.Add,
+				},
+				R:  ast.Number{Value: 4, Literal: "4"},
+				Op: ast.Mul,
+			},
+		},
+		{
+			in: "((2+3))*4", want: ast.Operator{
+				L: ast.Operator{
+					L:  ast.Number{Value: 2, Literal: "2"},
+					R:  ast.Number{Value: 3, Literal: "3"},
+					Op: ast.Add,
+				},
+				R:  ast.Number{Value: 4, Literal: "4"},
+				Op: ast.Mul,
+			},
+		},
+		{
+			in: "-3", want: ast.Unary{
+				X:  ast.Number{Value: 3, Literal: "3"},
+				Op: ast.Sub,
+			},
+		},
+		{
+			in: "+3", want: ast.Unary{
+				X:  ast.Number{Value: 3, Literal: "3"},
+				Op: ast.Add,
+			},
+		},
+		{
+			in: "- -4", want: ast.Unary{
+				X: ast.Unary{
+					X:  ast.Number{Value: 4, Literal: "4"},
+					Op: ast.Sub,
+				},
+				Op: ast.Sub,
+			},
+		},
+		{
+			in: "-2*3", want: ast.Operator{
+				L: ast.Unary{
+					X:  ast.Number{Value: 2, Literal: "2"},
+					Op: ast.Sub,
+				},
+				R:  ast.Number{Value: 3, Literal: "3"},
+				Op: ast.Mul,
+			},
+		},
+		{
+			in: "2*-3", want: ast.Operator{
+				L: ast.Number{Value: 2, Literal: "2"},
+				R: ast.Unary{
+					X:  ast.Number{Value: 3, Literal: "3"},
+					Op: ast.Sub,
+				},
+				Op: ast.Mul,
+			},
+		},
+		{
+			in: "-(1+2)", want: ast.Unary{
+				X: ast.Operator{
+					L:  ast.Number{Value: 1, Literal: "1"},
+					R:  ast.Number{Value: 2, Literal: "2"},
+					Op: ast.Add,
+				},
+				Op: ast.Sub,
+			},
+		},
+		{
+			in: "2^3^2", want: ast.Operator{
+				L: ast.Number{Value: 2, Literal: "2"},
+				R: ast.Operator{
+					L:  ast.Number{Value: 3, Literal: "3"},
+					R:  ast.Number{Value: 2, Literal: "2"},
+					Op: ast.Pow,
+				},
+				Op: ast.Pow,
+			},
+		},
+		{
+			in: "2*3^2", want: ast.Operator{
+				L: ast.Number{Value: 2, Literal: "2"},
+				R: ast.Operator{
+					L:  ast.Number{Value: 3, Literal: "3"},
+					R:  ast.Number{Value: 2, Literal: "2"},
+					Op: ast.Pow,
+				},
+				Op: ast.Mul,
+			},
+		},
+		{
+			in: "-2^2", want: ast.Unary{
+				X: ast.Operator{
+					L:  ast.Number{Value: 2, Literal: "2"},
+					R:  ast.Number{Value: 2, Literal: "2"},
+					Op: ast.Pow,
+				},
+				Op: ast.Sub,
+			},
+		},
+
+		{in: "", err: ErrInvalidSyntax},
+		{in: "-", err: ErrInvalidSyntax},
+		{in: "2*-", err: ErrInvalidSyntax},
+		{in: "2+", err: ErrInvalidSyntax},
+		{in: "(2+3", err: ErrInvalidSyntax},
+		{in: "((2)", err: ErrInvalidSyntax},
+		{in: "()", err: ErrInvalidSyntax},
+		{in: "1 2", err: ErrInvalidSyntax},
+		{in: "(1 2)", err: ErrInvalidSyntax},
+		{in: "1 + 2)", err: ErrInvalidSyntax},
+	}
+
+	for _, test := range tests {
+		p := NewParser(NewScanner(strings.NewReader(test.in)))
+		got, err := p.Expr()
+
+		expect.WithMessage(t, "in: %q", test.in).That(
+			is.Error(err, test.err),
+			is.DeepEqualTo(got, test.want, is.ExcludeTypes{reflect.TypeOf(token.Span{})}),
+		)
+	}
+}
+
+func TestParser_Program(t *testing.T) {
+	type testCase struct {
+		in   string
+		want *ast.Program
+		err  error
+	}
+
+	tests := []testCase{
+		{in: "", want: &ast.Program{}},
+		{in: ";\n;", want: &ast.Program{}},
+		{in: "1 + 2", want: &ast.Program{Stmts: []ast.Node{
+			ast.Operator{L: ast.Number{Value: 1, Literal: "1"}, R: ast.Number{Value: 2, Literal: "2"}, Op: ast.Add},
+		}}},
+		{in: "x = 2\ny = x *\n3; f(y)", want: &ast.Program{Stmts: []ast.Node{
+			ast.Assign{Name: "x", X: ast.Number{Value: 2, Literal: "2"}},
+			ast.Assign{Name: "y", X: ast.Operator{L: ast.Ident{Name: "x"}, R: ast.Number{Value: 3, Literal: "3"}, Op: ast.Mul}},
+			ast.Call{Name: "f", Args: []ast.Node{ast.Ident{Name: "y"}}},
+		}}},
+		{in: "x; y", want: &ast.Program{Stmts: []ast.Node{ast.Ident{Name: "x"}, ast.Ident{Name: "y"}}}},
+		{in: "x = ", err: ErrInvalidSyntax},
+		{in: "x = 1 = 2", err: ErrInvalidSyntax},
+		{in: "1 + x = 2", err: ErrInvalidSyntax},
+		{in: "(1;2)", err: ErrInvalidSyntax},
+		{in: "1\n2 +", err: ErrInvalidSyntax},
+	}
+
+	for _, test := range tests {
+		s := NewScanner(strings.NewReader(test.in))
+		s.Script()
+		got, err := NewParser(s).Program()
+
+		expect.WithMessage(t, "in: %q", test.in).That(
+			is.Error(err, test.err),
+			is.DeepEqualTo(got, test.want, is.ExcludeTypes{reflect.TypeOf(token.Span{})}),
+		)
+	}
+}
+
+func TestParser_spans(t *testing.T) {
+	got, err := NewParser(NewScanner(strings.NewReader("1 +\n-23"))).Expr()
+
+	expect.That(t,
+		is.NoError(err),
+		is.DeepEqualTo(got, ast.Node(ast.Operator{
+			L: ast.Number{
+				Value:   1,
+				Literal: "1",
+				Span:    token.Span{Start: token.Pos{Offset: 0, Line: 1, Column: 1}, End: token.Pos{Offset: 1, Line: 1, Column: 2}},
+			},
+			R: ast.Unary{
+				X: ast.Number{
+					Value:   23,
+					Literal: "23",
+					Span:    token.Span{Start: token.Pos{Offset: 5, Line: 2, Column: 2}, End: token.Pos{Offset: 7, Line: 2, Column: 4}},
+				},
+				Op:   ast.Sub,
+				Span: token.Span{Start: token.Pos{Offset: 4, Line: 2, Column: 1}, End: token.Pos{Offset: 5, Line: 2, Column: 2}},
+			},
+			Op:   ast.Add,
+			Span: token.Span{Start: token.Pos{Offset: 2, Line: 1, Column: 3}, End: token.Pos{Offset: 3, Line: 1, Column: 4}},
+		})),
+	)
+}
+
+func TestParser_errorPositions(t *testing.T) {
+	type testCase struct {
+		in   string
+		want string
+	}
+
+	tests := []testCase{
+		{in: "", want: "1:1: invalid syntax: unexpected end of input"},
+		{in: "2 +\n  )", want: "2:3: invalid syntax: unexpected \")\""},
+		{in: "(1 + (2 * 3)", want: "1:13: invalid syntax: expected ) but got end of input (unclosed ( at 1:1)"},
+		{in: "1 + #", want: "1:5: scan failed: invalid input rune: #"},
+		{in: "max(1,\n  2", want: "2:4: invalid syntax: expected ) but got end of input (unclosed ( at 1:4)"},
+		{in: "1 + (2, 3)", want: "1:7: invalid syntax: unexpected \",\""},
+		{in: "1; 2", want: "1:2: invalid syntax: unexpected \";\""},
+		{in: "x = 1", want: "1:3: invalid syntax: unexpected \"=\""},
+	}
+
+	for _, test := range tests {
+		_, err := NewParser(NewScanner(strings.NewReader(test.in))).Expr()
+
+		expect.WithMessage(t, "in: %q", test.in).That(
+			is.EqualTo(err.Error(), test.want),
+		)
+	}
+}
+
+func TestParser_Recover(t *testing.T) {
+	type testCase struct {
+		in   string
+		max  int
+		want ast.Node
+		errs []string
+	}
+
+	tests := []testCase{
+		{in: "1 + 2", want: ast.Operator{L: ast.Number{Value: 1, Literal: "1"}, R: ast.Number{Value: 2, Literal: "2"}, Op: ast.Add}},
+		{
+			in: "1 + * 2 + 3 4 + 5",
+			want: ast.Operator{
+				L: ast.Operator{
+					L: ast.Operator{
+						L:  ast.Number{Value: 1, Literal: "1"},
+						R:  ast.Operator{L: ast.Invalid{}, R: ast.Number{Value: 2, Literal: "2"}, Op: ast.Mul},
+						Op: ast.Add,
+					},
+					R:  ast.Number{Value: 3, Literal: "3"},
+					Op: ast.Add,
+				},
+				R:  ast.Number{Value: 5, Literal: "5"},
+				Op: ast.Add,
+			},
+			errs: []string{
+				"1:5: invalid syntax: unexpected \"*\"",
+				"1:13: invalid syntax: unexpected \"4\"",
+			},
+		},
+		{
+			in: "1 + # * (2 + )) + $",
+			want: ast.Operator{
+				L: ast.Operator{
+					L:  ast.Number{Value: 1, Literal: "1"},
+					R:  ast.Operator{L: ast.Invalid{}, R: ast.Operator{L: ast.Number{Value: 2, Literal: "2"}, R: ast.Invalid{}, Op: ast.Add}, Op: ast.Mul},
+					Op: ast.Add,
+				},
+				R:  ast.Invalid{},
+				Op: ast.Add,
+			},
+			errs: []string{
+				"1:5: scan failed: invalid input rune: #",
+				"1:14: invalid syntax: unexpected \")\"",
+				"1:15: invalid syntax: unexpected \")\"",
+				"1:19: scan failed: invalid input rune: $",
+			},
+		},
+		{
+			in:   "(1 + (2",
+			want: ast.Operator{L: ast.Number{Value: 1, Literal: "1"}, R: ast.Number{Value: 2, Literal: "2"}, Op: ast.Add},
+			errs: []string{
+				"1:8: invalid syntax: expected ) but got end of input (unclosed ( at 1:6)",
+				"1:8: invalid syntax: expected ) but got end of input (unclosed ( at 1:1)",
+			},
+		},
+		{
+			in:   "1 + + ",
+			want: ast.Operator{L: ast.Number{Value: 1, Literal: "1"}, R: ast.Unary{X: ast.Invalid{}, Op: ast.Add}, Op: ast.Add},
+			errs: []string{
+				"1:7: invalid syntax: unexpected end of input",
+			},
+		},
+		{
+			in:   "max(1 2, , 3",
+			want: ast.Call{Name: "max", Args: []ast.Node{ast.Number{Value: 1, Literal: "1"}, ast.Invalid{}, ast.Number{Value: 3, Literal: "3"}}},
+			errs: []string{
+				"1:7: invalid syntax: unexpected \"2\"",
+				"1:10: invalid syntax: unexpected \",\"",
+				"1:13: invalid syntax: expected ) but got end of input (unclosed ( at 1:4)",
+			},
+		},
+		{
+			in:   "1 + $",
+			max:  1,
+			want: ast.Operator{L: ast.Number{Value: 1, Literal: "1"}, R: ast.Invalid{}, Op: ast.Add},
+			errs: []string{"1:5: scan failed: invalid input rune: $"},
+		},
+		{
+			in:   "(1 + $",
+			max:  1,
+			want: ast.Operator{L: ast.Number{Value: 1, Literal: "1"}, R: ast.Invalid{}, Op: ast.Add},
+			errs: []string{"1:6: scan failed: invalid input rune: $"},
+		},
+		{
+			in:  "1 * (2 + $",
+			max: 1,
+			want: ast.Operator{
+				L:  ast.Number{Value: 1, Literal: "1"},
+				R:  ast.Operator{L: ast.Number{Value: 2, Literal: "2"}, R: ast.Invalid{}, Op: ast.Add},
+				Op: ast.Mul,
+			},
+			errs: []string{"1:10: scan failed: invalid input rune: $"},
+		},
+		{
+			in:   "max(1, $",
+			max:  1,
+			want: ast.Call{Name: "max", Args: []ast.Node{ast.Number{Value: 1, Literal: "1"}, ast.Invalid{}}},
+			errs: []string{"1:8: scan failed: invalid input rune: $"},
+		},
+		{
+			in:   "# $ % &",
+			max:  2,
+			want: ast.Invalid{},
+			errs: []string{
+				"1:1: scan failed: invalid input rune: #",
+				"1:3: scan failed: invalid input rune: $",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		p := NewParser(NewScanner(strings.NewReader(test.in)))
+		p.Recover(test.max)
+		got, err := p.Expr()
+
+		errs, _ := err.(ErrorList)
+		var msgs []string
+		for _, e := range errs {
+			msgs = append(msgs, e.Error())
+		}
+
+		expect.WithMessage(t, "in: %q", test.in).That(
+			is.DeepEqualTo(got, test.want, is.ExcludeTypes{reflect.TypeOf(token.Span{})}),
+			is.DeepEqualTo(msgs, test.errs),
+		)
+	}
+}
+
+func TestErrorList(t *testing.T) {
+	_, err := NewParser(NewScanner(strings.NewReader(""))).Expr()
+	l := ErrorList{err, err, err}
+
+	expect.That(t,
+		is.EqualTo(l.Error(), "1:1: invalid syntax: unexpected end of input (and 2 more errors)"),
+		is.Error(l, ErrInvalidSyntax),
+	)
+}
@@ -0,0 +1,105 @@
+package syntax
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/halimath/calc/token"
+)
+
+// ScanErrorKind classifies the errors described by ScanError.
+type ScanErrorKind int
+
+const (
+	// InvalidRune reports a rune that does not start any token.
+	InvalidRune ScanErrorKind = iota + 1
+	// MalformedNumber reports a number literal that does not denote a number, such as 1.2.3.
+	MalformedNumber
+	// ReadFailed reports an error reading from the underlying io.Reader.
+	ReadFailed
+
+	// The following kinds are only reported in strict mode.
+
+	// LeadingZero reports a number with an integer part starting with 0, such as 007.
+	LeadingZero
+	// LeadingDot reports a number starting with a decimal point, such as .5.
+	LeadingDot
+	// TrailingDot reports a decimal point not followed by a digit, such as 5.
+	TrailingDot
+	// RepeatedDot reports a number with more than one decimal point, such as 1.2.3.
+	RepeatedDot
+	// NonASCIIDigit reports a digit other than 0 to 9, such as the Arabic-Indic digit ٣.
+	NonASCIIDigit
+	// InvalidWhitespace reports whitespace other than space, \n, \t, \r, \f and \b.
+	InvalidWhitespace
+)
+
+func (k ScanErrorKind) String() string {
+	switch k {
+	case InvalidRune:
+		return "invalid rune"
+	case MalformedNumber:
+		return "malformed number"
+	case ReadFailed:
+		return "read failed"
+	case LeadingZero:
+		return "leading zero"
+	case LeadingDot:
+		return "leading dot"
+	case TrailingDot:
+		return "trailing dot"
+	case RepeatedDot:
+		return "repeated dot"
+	case NonASCIIDigit:
+		return "non-ASCII digit"
+	case InvalidWhitespace:
+		return "invalid whitespace"
+	default:
+		return fmt.Sprintf("ScanErrorKind(%d)", int(k))
+	}
+}
+
+// ScanError describes input that does not form a valid token. It wraps ErrScanFailed and Err, if set.
+type ScanError struct {
+	Kind ScanErrorKind
+	Span token.Span
+	// Text is the offending input, i.e. the invalid rune or the malformed number literal.
+	Text string
+	// Err is the underlying error, such as the one reported by the io.Reader for ReadFailed.
+	Err error
+}
+
+// Message returns the error's message without the position prefix.
+func (e *ScanError) Message() string {
+	switch e.Kind {
+	case InvalidRune:
+		return fmt.Sprintf("%v: invalid input rune: %s", ErrScanFailed, e.Text)
+	case MalformedNumber:
+		return fmt.Sprintf("%v: malformed number: %q", ErrScanFailed, e.Text)
+	case LeadingZero:
+		return fmt.Sprintf("%v: leading zero in number: %q", ErrScanFailed, e.Text)
+	case LeadingDot:
+		return fmt.Sprintf("%v: number must start with a digit: %q", ErrScanFailed, e.Text)
+	case TrailingDot:
+		return fmt.Sprintf("%v: missing digit after decimal point: %q", ErrScanFailed, e.Text)
+	case RepeatedDot:
+		return fmt.Sprintf("%v: more than one decimal point in number: %q", ErrScanFailed, e.Text)
+	case NonASCIIDigit:
+		r, _ := utf8.DecodeRuneInString(e.Text)
+		return fmt.Sprintf("%v: non-ASCII digit %c (%U)", ErrScanFailed, r, r)
+	case InvalidWhitespace:
+		r, _ := utf8.DecodeRuneInString(e.Text)
+		return fmt.Sprintf("%v: invalid whitespace %U", ErrScanFailed, r)
+	default:
+		return fmt.Sprintf("%v: %v", ErrScanFailed, e.Err)
+	}
+}
+
+func (e *ScanError) Error() string { return fmt.Sprintf("%s: %s", e.Span, e.Message()) }
+
+func (e *ScanError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{ErrScanFailed}
+	}
+	return []error{ErrScanFailed, e.Err}
+}
@@ -0,0 +1,420 @@
+package syntax
+
+import (
+	"errors"
+	"io"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/halimath/calc/token"
+)
+
+// ErrScanFailed is returned when the lexer hits invalid input.
+var ErrScanFailed = errors.New("scan failed")
+
+const (
+	// bufferSize is the size of the buffer a Scanner reads input into. The buffer grows if a single token does
+	// not fit into it.
+	bufferSize = 64 * 1024
+	// minBufferSize is the size of the smallest buffer, which is used for short input of known length.
+	minBufferSize = 64
+	// maxNames limits the number of identifiers interned by a Scanner.
+	maxNames = 1024
+)
+
+// Scanner implements scanning an io.Reader for tokens. It tracks the position of each token in the input.
+//
+// A Scanner reads the input into a buffer and scans the bytes in place. Numbers are parsed right from the
+// buffer and the names of identifiers are interned, so scanning does not allocate except for the literals of
+// numbers, which are omitted in ValuesOnly mode. Use Reset to scan more input reusing the buffer.
+type Scanner struct {
+	r io.Reader
+
+	// buf[off:end] contains the input read but not scanned yet. mark is the index of the first byte of the
+	// token being scanned, which is kept when refilling buf, or -1.
+	buf            []byte
+	off, end, mark int
+	// size is the size of the rune read last, which is unread by moving off back.
+	size int
+	// err is the error returned by r, which is reported once buf is exhausted.
+	err error
+
+	// pos is the offset of the next rune to read, prev the offset of the rune read last and after the offset
+	// following the token returned last. lines records the line breaks read so far to derive positions.
+	pos, prev, after int
+	lines            token.Lines
+
+	// strict enables strict mode and values ValuesOnly mode.
+	strict bool
+	values bool
+
+	// script enables script mode. last is the type of the token returned last and depth the number of
+	// parenthesis open in script mode.
+	script bool
+	last   token.Type
+	depth  int
+
+	// names contains the names of the identifiers scanned so far.
+	names map[string]string
+}
+
+// NewScanner creates a new Scanner consuming input from r.
+func NewScanner(r io.Reader) *Scanner {
+	size := bufferSize
+	if l, ok := r.(interface{ Len() int }); ok {
+		// Do not allocate more than needed for short input, such as a strings.Reader.
+		size = min(max(l.Len()+utf8.UTFMax, minBufferSize), bufferSize)
+	}
+
+	s := Scanner{buf: make([]byte, size)}
+	s.Reset(r)
+	return &s
+}
+
+// Reset discards all state of s and switches it to scan r, keeping its buffer and its modes. Resetting a
+// Scanner rather than creating a new one avoids allocating a buffer for every input.
+func (s *Scanner) Reset(r io.Reader) {
+	s.r = r
+	s.off, s.end, s.mark, s.size = 0, 0, -1, 0
+	s.err = nil
+	s.pos, s.prev, s.after = 0, 0, 0
+	s.lines.Reset()
+	s.last, s.depth = 0, 0
+}
+
+// ValuesOnly switches s to ValuesOnly mode, in which number tokens carry their Value only and their Literal
+// is empty. Scanning numbers then does not allocate at all, which suits evaluating using float64 numbers.
+// ValuesOnly must be called before scanning the first token.
+func (s *Scanner) ValuesOnly() {
+	s.values = true
+}
+
+// Next consumes the next token from l and returns it. If no more tokens are available, the returned token
+// is the zero value and io.EOF is returned as the error. In any other non-nil value represents an scanning
+// error. Every token carries the offset and every error the span of input it refers to.
+func (s *Scanner) Next() (token.Token, error) {
+	tok, err := s.next()
+	if err == nil {
+		s.after = s.pos
+	}
+	if s.script && err == nil {
+		switch tok.Type {
+		case token.LParen:
+			s.depth++
+		case token.RParen:
+			s.depth = max(s.depth-1, 0)
+		}
+		s.last = tok.Type
+	}
+	return tok, err
+}
+
+// next implements Next.
+func (s *Scanner) next() (token.Token, error) {
+	for {
+		s.skipSpace()
+
+		r, err := s.read()
+		if err != nil {
+			return token.Token{Offset: s.pos}, s.readError(err)
+		}
+
+		if r == '\n' && s.script {
+			if s.terminates() {
+				return token.Token{Type: token.Semicolon, Literal: "\n", Offset: s.prev}, nil
+			}
+			continue
+		}
+
+		if isSeparator(r) || (!s.strict && unicode.IsSpace(r)) {
+			continue
+		}
+
+		if isDigit(r) || r == '.' {
+			if s.strict {
+				return s.scanStrictNumber(r)
+			}
+			return s.scanNumber()
+		}
+
+		if s.strict && unicode.IsSpace(r) {
+			return token.Token{Offset: s.prev}, &ScanError{Kind: InvalidWhitespace, Span: s.span(s.prev, s.pos), Text: string(r)}
+		}
+
+		if isLetter(r) {
+			return s.consumeIdent()
+		}
+
+		switch r {
+		case '+':
+			return token.Token{Type: token.Add, Offset: s.prev}, nil
+		case '-':
+			return token.Token{Type: token.Sub, Offset: s.prev}, nil
+		case '*':
+			return token.Token{Type: token.Mul, Offset: s.prev}, nil
+		case '/':
+			return token.Token{Type: token.Div, Offset: s.prev}, nil
+		case '^':
+			return token.Token{Type: token.Pow, Offset: s.prev}, nil
+		case '(':
+			return token.Token{Type: token.LParen, Offset: s.prev}, nil
+		case ')':
+			return token.Token{Type: token.RParen, Offset: s.prev}, nil
+		case ',':
+			return token.Token{Type: token.Comma, Offset: s.prev}, nil
+		case '=':
+			return token.Token{Type: token.Assign, Offset: s.prev}, nil
+		case ';':
+			return token.Token{Type: token.Semicolon, Offset: s.prev}, nil
+		default:
+			return token.Token{Offset: s.prev}, &ScanError{Kind: InvalidRune, Span: s.span(s.prev, s.pos), Text: string(r)}
+		}
+	}
+}
+
+// Script switches s to script mode, in which a line break terminates a statement the same way a semicolon
+// does: it is returned as a token.Semicolon with the Literal "\n" if it follows a number, an identifier or a
+// closing parenthesis outside of any parenthesis. Any other line break is skipped as whitespace, so an
+// expression continues on the next line following an operator, i.e. 1 +<newline>2 is a single statement.
+// Script must be called before scanning the first token.
+func (s *Scanner) Script() {
+	s.script = true
+}
+
+// terminates returns whether a line break read in script mode terminates a statement.
+func (s *Scanner) terminates() bool {
+	if s.depth > 0 {
+		return false
+	}
+	switch s.last {
+	case token.Number, token.Ident, token.RParen:
+		return true
+	default:
+		return false
+	}
+}
+
+// Pos returns the position of offset, which must not exceed the input scanned so far. Tokens carry their
+// offset only, so their positions are derived on demand.
+func (s *Scanner) Pos(offset int) token.Pos {
+	return s.lines.Pos(offset)
+}
+
+// Offset returns the offset following the token returned last, or 0 if none has been returned yet.
+func (s *Scanner) Offset() int {
+	return s.after
+}
+
+// span returns the span from start to end.
+func (s *Scanner) span(start, end int) token.Span {
+	return s.lines.Span(start, end)
+}
+
+// read reads the next rune and advances s.pos past it, recording line breaks and multi-byte runes in
+// s.lines. It returns io.EOF at the end of input.
+func (s *Scanner) read() (rune, error) {
+	if s.end-s.off < utf8.UTFMax && s.err == nil {
+		// Make sure a complete rune is buffered unless the input ends.
+		s.fill()
+	}
+	if s.off == s.end {
+		err := s.err
+		// Report a read error only once.
+		s.err = io.EOF
+		return 0, err
+	}
+
+	r, size := rune(s.buf[s.off]), 1
+	if r >= utf8.RuneSelf {
+		r, size = utf8.DecodeRune(s.buf[s.off:s.end])
+	}
+	s.off += size
+	s.size = size
+
+	s.prev = s.pos
+	s.pos += size
+	if r == '\n' {
+		s.lines.AddLine(s.pos)
+	} else if size > 1 {
+		s.lines.AddRune(s.prev, size)
+	}
+
+	return r, nil
+}
+
+// skipSpace skips ASCII whitespace right in the buffer, which is faster than reading it rune by rune. Line
+// breaks are left to read in script mode, where they may terminate a statement.
+func (s *Scanner) skipSpace() {
+	for s.off < s.end {
+		switch s.buf[s.off] {
+		case ' ', '\t', '\r', '\f', '\b':
+		case '\n':
+			if s.script {
+				return
+			}
+			s.lines.AddLine(s.pos + 1)
+		default:
+			return
+		}
+		s.off++
+		s.pos++
+	}
+}
+
+// unread unreads the rune read last. It must only be called once after read.
+func (s *Scanner) unread() {
+	s.off -= s.size
+	s.pos = s.prev
+}
+
+// fill reads more input into buf. It discards the bytes scanned already except for those of the token being
+// scanned, growing buf if that token fills all of it.
+func (s *Scanner) fill() {
+	keep := s.off
+	if s.mark >= 0 {
+		keep = s.mark
+	}
+
+	switch {
+	case keep > 0:
+		s.end = copy(s.buf, s.buf[keep:s.end])
+		s.off -= keep
+		if s.mark >= 0 {
+			s.mark = 0
+		}
+	case s.end == len(s.buf):
+		buf := make([]byte, 2*len(s.buf))
+		copy(buf, s.buf)
+		s.buf = buf
+	}
+
+	// Some readers return no data without an error, so retry a few times.
+	for i := 0; i < 100; i++ {
+		n, err := s.r.Read(s.buf[s.end:])
+		s.end += n
+		if err != nil {
+			s.err = err
+			return
+		}
+		if n > 0 {
+			return
+		}
+	}
+	s.err = io.ErrNoProgress
+}
+
+// readError returns the error to report for err returned by read, which is io.EOF at the end of input.
+func (s *Scanner) readError(err error) error {
+	s.mark = -1
+	if errors.Is(err, io.EOF) {
+		return err
+	}
+	return &ScanError{Kind: ReadFailed, Span: s.span(s.pos, s.pos), Err: err}
+}
+
+// scanNumber scans a number starting with the rune read last, which is a digit or a decimal point.
+func (s *Scanner) scanNumber() (token.Token, error) {
+	s.mark = s.off - s.size
+	start := s.prev
+
+	for {
+		// Consume ASCII digits and decimal points right in the buffer, leaving any other rune and refilling the
+		// buffer to read.
+		n := s.off
+		for n < s.end && (('0' <= s.buf[n] && s.buf[n] <= '9') || s.buf[n] == '.') {
+			n++
+		}
+		s.pos += n - s.off
+		s.off = n
+
+		r, err := s.read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return token.Token{Offset: s.pos}, s.readError(err)
+		}
+
+		if !isDigit(r) && r != '.' {
+			s.unread()
+			break
+		}
+	}
+
+	return s.number(start)
+}
+
+// number returns the number in buf[s.mark:s.off], which is located from start to s.pos.
+func (s *Scanner) number(start int) (token.Token, error) {
+	lit := s.buf[s.mark:s.off]
+	s.mark = -1
+
+	val, err := parseFloat(lit)
+	if err != nil {
+		return token.Token{Offset: start}, &ScanError{Kind: MalformedNumber, Span: s.span(start, s.pos), Text: string(lit), Err: err}
+	}
+
+	tok := token.Token{Type: token.Number, Value: val, Offset: start}
+	if !s.values {
+		tok.Literal = string(lit)
+	}
+	return tok, nil
+}
+
+// consumeIdent consumes an identifier starting with the rune read last.
+func (s *Scanner) consumeIdent() (token.Token, error) {
+	s.mark = s.off - s.size
+	start := s.prev
+
+	for {
+		r, err := s.read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return token.Token{Offset: s.pos}, s.readError(err)
+		}
+
+		if !isLetter(r) && (r < '0' || r > '9') {
+			s.unread()
+			break
+		}
+	}
+
+	name := s.intern(s.buf[s.mark:s.off])
+	s.mark = -1
+
+	return token.Token{Type: token.Ident, Literal: name, Offset: start}, nil
+}
+
+// intern returns b as a string, reusing the string returned for the same name before.
+func (s *Scanner) intern(b []byte) string {
+	if name, ok := s.names[string(b)]; ok {
+		return name
+	}
+
+	name := string(b)
+	if s.names == nil {
+		s.names = make(map[string]string)
+	}
+	if len(s.names) < maxNames {
+		s.names[name] = name
+	}
+	return name
+}
+
+// isDigit returns whether r is a decimal digit of any script.
+func isDigit(r rune) bool {
+	if r < utf8.RuneSelf {
+		return '0' <= r && r <= '9'
+	}
+	return unicode.IsDigit(r)
+}
+
+// isLetter returns whether r may start an identifier, which is the case for letters and the underscore.
+func isLetter(r rune) bool {
+	if r < utf8.RuneSelf {
+		return r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
+	}
+	return unicode.IsLetter(r)
+}
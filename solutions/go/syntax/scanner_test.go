@@ -0,0 +1,413 @@
+package syntax
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/halimath/calc/token"
+	"github.com/halimath/expect"
+	"github.com/halimath/expect/is"
+)
+
+func TestScanner(t *testing.T) {
+	type testCase struct {
+		in   string
+		want []token.Token
+		err  error
+	}
+
+	tests := []testCase{
+		{in: ""},
+		{in: "#", err: ErrScanFailed},
+		{in: "  2.3", want: []token.Token{
+			{Type: token.Number, Value: 2.3, Literal: "2.3"},
+		}},
+		{in: "+", want: []token.Token{
+			{Type: token.Add},
+		}},
+		{in: "-", want: []token.Token{
+			{Type: token.Sub},
+		}},
+		{in: "*", want: []token.Token{
+			{Type: token.Mul},
+		}},
+		{in: "/", want: []token.Token{
+			{Type: token.Div},
+		}},
+		{in: "^", want: []token.Token{
+			{Type: token.Pow},
+		}},
+		{in: "(", want: []token.Token{
+			{Type: token.LParen},
+		}},
+		{in: ")", want: []token.Token{
+			{Type: token.RParen},
+		}},
+		{in: ",", want: []token.Token{
+			{Type: token.Comma},
+		}},
+		{in: "x_1 * Größe2", want: []token.Token{
+			{Type: token.Ident, Literal: "x_1"},
+			{Type: token.Mul},
+			{Type: token.Ident, Literal: "Größe2"},
+		}},
+		{in: "2x", want: []token.Token{
+			{Type: token.Number, Value: 2, Literal: "2"},
+			{Type: token.Ident, Literal: "x"},
+		}},
+		{in: "2+3*4", want: []token.Token{
+			{Type: token.Number, Value: 2, Literal: "2"},
+			{Type: token.Add},
+			{Type: token.Number, Value: 3, Literal: "3"},
+			{Type: token.Mul},
+			{Type: token.Number, Value: 4, Literal: "4"},
+		}},
+		{in: "2 + 3 * 4 ", want: []token.Token{
+			{Type: token.Number, Value: 2, Literal: "2"},
+			{Type: token.Add},
+			{Type: token.Number, Value: 3, Literal: "3"},
+			{Type: token.Mul},
+			{Type: token.Number, Value: 4, Literal: "4"},
+		}},
+	}
+
+	for _, test := range tests {
+		s := NewScanner(strings.NewReader(test.in))
+
+		got, err := consumeAll(s)
+		expect.WithMessage(t, "input: %q", test.in).That(
+			is.Error(err, test.err),
+			is.DeepEqualTo(withoutOffsets(got), test.want),
+		)
+	}
+}
+
+func TestScanner_script(t *testing.T) {
+	type testCase struct {
+		in   string
+		want []token.Token
+	}
+
+	tests := []testCase{
+		{in: "x = 1; x", want: []token.Token{
+			{Type: token.Ident, Literal: "x"},
+			{Type: token.Assign},
+			{Type: token.Number, Value: 1, Literal: "1"},
+			{Type: token.Semicolon},
+			{Type: token.Ident, Literal: "x"},
+		}},
+		{in: "1\n\n2)\n", want: []token.Token{
+			{Type: token.Number, Value: 1, Literal: "1"},
+			{Type: token.Semicolon, Literal: "\n"},
+			{Type: token.Number, Value: 2, Literal: "2"},
+			{Type: token.RParen},
+			{Type: token.Semicolon, Literal: "\n"},
+		}},
+		{in: "1 +\n(2\n)", want: []token.Token{
+			{Type: token.Number, Value: 1, Literal: "1"},
+			{Type: token.Add},
+			{Type: token.LParen},
+			{Type: token.Number, Value: 2, Literal: "2"},
+			{Type: token.RParen},
+		}},
+	}
+
+	for _, test := range tests {
+		s := NewScanner(strings.NewReader(test.in))
+		s.Script()
+
+		got, err := consumeAll(s)
+		expect.WithMessage(t, "input: %q", test.in).That(
+			is.NoError(err),
+			is.DeepEqualTo(withoutOffsets(got), test.want),
+		)
+	}
+}
+
+func TestScanner_spans(t *testing.T) {
+	s := NewScanner(strings.NewReader("12 +\n (3.5*\t4)"))
+	got, err := consumeAll(s)
+	expect.That(t, is.NoError(err))
+
+	var spans []token.Span
+	for _, tok := range got {
+		spans = append(spans, token.Span{Start: s.Pos(tok.Offset), End: s.Pos(tok.End())})
+	}
+
+	expect.That(t, is.DeepEqualTo(spans, []token.Span{
+		{Start: token.Pos{Offset: 0, Line: 1, Column: 1}, End: token.Pos{Offset: 2, Line: 1, Column: 3}},
+		{Start: token.Pos{Offset: 3, Line: 1, Column: 4}, End: token.Pos{Offset: 4, Line: 1, Column: 5}},
+		{Start: token.Pos{Offset: 6, Line: 2, Column: 2}, End: token.Pos{Offset: 7, Line: 2, Column: 3}},
+		{Start: token.Pos{Offset: 7, Line: 2, Column: 3}, End: token.Pos{Offset: 10, Line: 2, Column: 6}},
+		{Start: token.Pos{Offset: 10, Line: 2, Column: 6}, End: token.Pos{Offset: 11, Line: 2, Column: 7}},
+		{Start: token.Pos{Offset: 12, Line: 2, Column: 8}, End: token.Pos{Offset: 13, Line: 2, Column: 9}},
+		{Start: token.Pos{Offset: 13, Line: 2, Column: 9}, End: token.Pos{Offset: 14, Line: 2, Column: 10}},
+	}))
+}
+
+func TestScanner_errorPosition(t *testing.T) {
+	s := NewScanner(strings.NewReader("1 +\n  #"))
+	s.Next()
+	s.Next()
+	tok, err := s.Next()
+
+	expect.That(t,
+		is.Error(err, ErrScanFailed),
+		is.EqualTo(s.Pos(tok.Offset), token.Pos{Offset: 6, Line: 2, Column: 3}),
+		is.StringContaining(err.Error(), "2:3"),
+	)
+}
+
+func TestScanner_strict(t *testing.T) {
+	type testCase struct {
+		in   string
+		want []token.Token
+		kind ScanErrorKind
+		pos  token.Pos
+	}
+
+	tests := []testCase{
+		{in: "0", want: []token.Token{{Type: token.Number, Value: 0, Literal: "0"}}},
+		{in: "0.05", want: []token.Token{{Type: token.Number, Value: 0.05, Literal: "0.05"}}},
+		{in: "807.1328", want: []token.Token{{Type: token.Number, Value: 807.1328, Literal: "807.1328"}}},
+		{in: "10+0.5", want: []token.Token{{Type: token.Number, Value: 10, Literal: "10"}, {Type: token.Add}, {Type: token.Number, Value: 0.5, Literal: "0.5"}}},
+		{in: "1 \n\t\r\f\b+ 2", want: []token.Token{{Type: token.Number, Value: 1, Literal: "1"}, {Type: token.Add}, {Type: token.Number, Value: 2, Literal: "2"}}},
+
+		{in: "007", kind: LeadingZero, pos: token.Pos{Offset: 1, Line: 1, Column: 2}},
+		{in: "2 * 01.5", kind: LeadingZero, pos: token.Pos{Offset: 5, Line: 1, Column: 6}},
+		{in: ".5", kind: LeadingDot, pos: token.Pos{Offset: 0, Line: 1, Column: 1}},
+		{in: "5.", kind: TrailingDot, pos: token.Pos{Offset: 1, Line: 1, Column: 2}},
+		{in: "5. + 1", kind: TrailingDot, pos: token.Pos{Offset: 1, Line: 1, Column: 2}},
+		{in: "5..1", kind: RepeatedDot, pos: token.Pos{Offset: 2, Line: 1, Column: 3}},
+		{in: "1.2.3", kind: RepeatedDot, pos: token.Pos{Offset: 3, Line: 1, Column: 4}},
+		{in: "1٣", kind: NonASCIIDigit, pos: token.Pos{Offset: 1, Line: 1, Column: 2}},
+		{in: "٣", kind: NonASCIIDigit, pos: token.Pos{Offset: 0, Line: 1, Column: 1}},
+		{in: "1 +\v2", kind: InvalidWhitespace, pos: token.Pos{Offset: 3, Line: 1, Column: 4}},
+		{in: "1\u00a0+ 2", kind: InvalidWhitespace, pos: token.Pos{Offset: 1, Line: 1, Column: 2}},
+	}
+
+	for _, test := range tests {
+		s := NewScanner(strings.NewReader(test.in))
+		s.Strict()
+
+		got, err := consumeAll(s)
+
+		var scanErr *ScanError
+		if errors.As(err, &scanErr) {
+			expect.WithMessage(t, "input: %q", test.in).That(
+				is.Error(err, ErrScanFailed),
+				is.EqualTo(scanErr.Kind, test.kind),
+				is.EqualTo(scanErr.Span.Start, test.pos),
+			)
+			continue
+		}
+
+		expect.WithMessage(t, "input: %q", test.in).That(
+			is.NoError(err),
+			is.EqualTo(test.kind, 0),
+			is.DeepEqualTo(withoutOffsets(got), test.want),
+		)
+	}
+}
+
+func TestScanner_strictMessages(t *testing.T) {
+	type testCase struct {
+		in   string
+		want string
+	}
+
+	tests := []testCase{
+		{in: "007", want: "1:2: scan failed: leading zero in number: \"00\""},
+		{in: "5.", want: "1:2: scan failed: missing digit after decimal point: \"5.\""},
+		{in: "1.2.3", want: "1:4: scan failed: more than one decimal point in number: \"1.2.\""},
+		{in: "٣", want: "1:1: scan failed: non-ASCII digit ٣ (U+0663)"},
+		{in: "\u00a0", want: "1:1: scan failed: invalid whitespace U+00A0"},
+	}
+
+	for _, test := range tests {
+		s := NewScanner(strings.NewReader(test.in))
+		s.Strict()
+
+		_, err := consumeAll(s)
+
+		expect.WithMessage(t, "input: %q", test.in).That(
+			is.EqualTo(err.Error(), test.want),
+		)
+	}
+}
+
+func TestScanner_strictContinuesAfterError(t *testing.T) {
+	s := NewScanner(strings.NewReader("1.2.3 + 4"))
+	s.Strict()
+
+	_, err := s.Next()
+	expect.That(t, is.Error(err, ErrScanFailed))
+
+	got, err := consumeAll(s)
+	expect.That(t,
+		is.NoError(err),
+		is.DeepEqualTo(withoutOffsets(got), []token.Token{{Type: token.Add}, {Type: token.Number, Value: 4, Literal: "4"}}),
+	)
+}
+
+func TestScanner_backspace(t *testing.T) {
+	got, err := consumeAll(NewScanner(strings.NewReader("1\b+\b2")))
+
+	expect.That(t,
+		is.NoError(err),
+		is.DeepEqualTo(withoutOffsets(got), []token.Token{{Type: token.Number, Value: 1, Literal: "1"}, {Type: token.Add}, {Type: token.Number, Value: 2, Literal: "2"}}),
+	)
+}
+
+func TestScanner_valuesOnly(t *testing.T) {
+	input := strings.Repeat("12.5 * (3 + x) - 0.25 / y ^ 2\n", 100)
+	r := strings.NewReader(input)
+	s := NewScanner(r)
+	s.ValuesOnly()
+
+	allocs := testing.AllocsPerRun(10, func() {
+		r.Reset(input)
+		s.Reset(r)
+		for {
+			if _, err := s.Next(); err != nil {
+				break
+			}
+		}
+	})
+
+	s.Reset(strings.NewReader("1.5 + x"))
+	got, err := consumeAll(s)
+
+	expect.That(t,
+		is.EqualTo(allocs, 0),
+		is.NoError(err),
+		is.DeepEqualTo(withoutOffsets(got), []token.Token{{Type: token.Number, Value: 1.5}, {Type: token.Add}, {Type: token.Ident, Literal: "x"}}),
+	)
+}
+
+func TestScanner_Reset(t *testing.T) {
+	s := NewScanner(strings.NewReader("1 +"))
+	_, err := consumeAll(s)
+	expect.That(t, is.NoError(err))
+
+	s.Reset(strings.NewReader("\n  2"))
+	got, err := consumeAll(s)
+
+	expect.That(t,
+		is.NoError(err),
+		is.DeepEqualTo(got, []token.Token{{Type: token.Number, Value: 2, Literal: "2", Offset: 3}}),
+		is.EqualTo(s.Pos(3), token.Pos{Offset: 3, Line: 2, Column: 3}),
+		is.EqualTo(s.Offset(), 4),
+	)
+}
+
+func TestScanner_Pos(t *testing.T) {
+	s := NewScanner(strings.NewReader("ä + 1\n\n  €x €"))
+	got, err := consumeAll(s)
+
+	var positions []token.Pos
+	for _, tok := range got {
+		positions = append(positions, s.Pos(tok.Offset))
+	}
+
+	expect.That(t,
+		is.Error(err, ErrScanFailed),
+		is.DeepEqualTo(positions, []token.Pos{
+			{Offset: 0, Line: 1, Column: 1},
+			{Offset: 3, Line: 1, Column: 3},
+			{Offset: 5, Line: 1, Column: 5},
+		}),
+		is.StringContaining(err.Error(), "3:3"),
+	)
+}
+
+func TestScanner_oneByteReader(t *testing.T) {
+	// Whitespace and numbers are scanned right in the buffer, which holds a single byte at a time here.
+	in := " 12.5 +\n\t 3 *  \n 0.25"
+	s := NewScanner(iotest.OneByteReader(strings.NewReader(in)))
+	got, err := consumeAll(s)
+
+	expect.That(t,
+		is.NoError(err),
+		is.DeepEqualTo(got, []token.Token{
+			{Type: token.Number, Value: 12.5, Literal: "12.5", Offset: 1},
+			{Type: token.Add, Offset: 6},
+			{Type: token.Number, Value: 3, Literal: "3", Offset: 10},
+			{Type: token.Mul, Offset: 12},
+			{Type: token.Number, Value: 0.25, Literal: "0.25", Offset: 17},
+		}),
+		is.EqualTo(s.Pos(17), token.Pos{Offset: 17, Line: 3, Column: 2}),
+	)
+}
+
+func TestScanner_tokenExceedingBuffer(t *testing.T) {
+	// A reader without a Len method gets a buffer of bufferSize bytes, which the identifier does not fit into.
+	name := strings.Repeat("x", 3*bufferSize)
+	number := "0." + strings.Repeat("0", 2*bufferSize) + "1"
+	got, err := consumeAll(NewScanner(iotest.HalfReader(strings.NewReader(name + " - " + number))))
+
+	expect.That(t,
+		is.NoError(err),
+		is.DeepEqualTo(withoutOffsets(got), []token.Token{
+			{Type: token.Ident, Literal: name},
+			{Type: token.Sub},
+			{Type: token.Number, Value: 0, Literal: number},
+		}),
+	)
+}
+
+// withoutOffsets returns toks with the offsets set to 0, so that tokens are compared regardless of their
+// location.
+func withoutOffsets(toks []token.Token) []token.Token {
+	for i := range toks {
+		toks[i].Offset = 0
+	}
+	return toks
+}
+
+func consumeAll(s *Scanner) (toks []token.Token, err error) {
+	var t token.Token
+	for {
+		t, err = s.Next()
+		if errors.Is(err, io.EOF) {
+			err = nil
+			return
+		}
+
+		if err != nil {
+			return
+		}
+
+		toks = append(toks, t)
+	}
+}
+
+func BenchmarkScanner(b *testing.B) {
+	content, err := os.ReadFile("../../../testdata/10m")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	r := bytes.NewReader(content)
+	s := NewScanner(r)
+	s.ValuesOnly()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		r.Reset(content)
+		s.Reset(r)
+		for {
+			if _, err := s.Next(); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
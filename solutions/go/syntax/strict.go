@@ -0,0 +1,138 @@
+package syntax
+
+import (
+	"errors"
+	"io"
+	"unicode"
+
+	"github.com/halimath/calc/token"
+)
+
+// Strict switches s to strict mode, in which it only accepts input conforming to the number and S productions
+// of the grammar: numbers must not have leading zeros and consist of ASCII digits with decimal points that are
+// followed by at least one digit. Unlike the grammar, strict mode allows at most one decimal point, as a
+// number such as 1.2.3 does not denote a number. Whitespace is limited to space, \n, \t, \r, \f and \b.
+// Strict must be called before scanning the first token.
+func (s *Scanner) Strict() {
+	s.strict = true
+}
+
+// isSeparator returns whether r is whitespace as defined by the S production.
+func isSeparator(r rune) bool {
+	switch r {
+	case ' ', '\n', '\t', '\r', '\f', '\b':
+		return true
+	default:
+		return false
+	}
+}
+
+// numberState is a state of the state machine scanning numbers in strict mode.
+type numberState int
+
+const (
+	// numberStart is the initial state before reading the first rune.
+	numberStart numberState = iota
+	// numberZero follows a leading 0, which may only be followed by a decimal point.
+	numberZero
+	// numberInteger follows a non-zero digit and any number of digits.
+	numberInteger
+	// numberDot follows the decimal point, which must be followed by a digit.
+	numberDot
+	// numberFraction follows at least one fractional digit.
+	numberFraction
+)
+
+// scanStrictNumber scans a number starting with r, which has just been read, according to the number
+// production.
+func (s *Scanner) scanStrictNumber(r rune) (token.Token, error) {
+	s.mark = s.off - s.size
+	start := s.prev
+	state := numberStart
+	// dot is the offset of the decimal point.
+	dot := -1
+
+	for {
+		var kind ScanErrorKind
+
+		switch {
+		case r >= '0' && r <= '9':
+			switch state {
+			case numberStart:
+				state = numberInteger
+				if r == '0' {
+					state = numberZero
+				}
+			case numberZero:
+				kind = LeadingZero
+			case numberDot:
+				state = numberFraction
+			}
+
+		case r == '.':
+			switch state {
+			case numberStart:
+				kind = LeadingDot
+			case numberZero, numberInteger:
+				state = numberDot
+				dot = s.prev
+			default:
+				kind = RepeatedDot
+			}
+
+		case unicode.IsDigit(r):
+			kind = NonASCIIDigit
+
+		default:
+			// r does not belong to the number.
+			s.unread()
+			return s.endStrictNumber(state, start, dot)
+		}
+
+		if kind != 0 {
+			at, span := s.prev, s.span(s.prev, s.pos)
+			// The text reported includes r.
+			text := string(s.buf[s.mark:s.off])
+			if kind == NonASCIIDigit {
+				text = string(r)
+			}
+			s.mark = -1
+			s.skipNumber()
+			return token.Token{Offset: at}, &ScanError{Kind: kind, Span: span, Text: text}
+		}
+
+		var err error
+		r, err = s.read()
+		if errors.Is(err, io.EOF) {
+			return s.endStrictNumber(state, start, dot)
+		}
+		if err != nil {
+			return token.Token{Offset: s.pos}, s.readError(err)
+		}
+	}
+}
+
+// endStrictNumber completes scanning a number located from start in the given state.
+func (s *Scanner) endStrictNumber(state numberState, start, dot int) (token.Token, error) {
+	if state == numberDot {
+		text := string(s.buf[s.mark:s.off])
+		s.mark = -1
+		return token.Token{Offset: dot}, &ScanError{Kind: TrailingDot, Span: s.span(dot, dot+1), Text: text}
+	}
+
+	return s.number(start)
+}
+
+// skipNumber skips the remainder of a malformed number, so that scanning continues after it.
+func (s *Scanner) skipNumber() {
+	for {
+		r, err := s.read()
+		if err != nil {
+			return
+		}
+		if !isDigit(r) && r != '.' {
+			s.unread()
+			return
+		}
+	}
+}
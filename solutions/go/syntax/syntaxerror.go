@@ -0,0 +1,88 @@
+package syntax
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/halimath/calc/token"
+)
+
+// SyntaxErrorKind classifies the errors described by SyntaxError.
+type SyntaxErrorKind int
+
+const (
+	// UnexpectedToken reports a token that is not valid at its position, including a premature end of input.
+	UnexpectedToken SyntaxErrorKind = iota + 1
+	// UnclosedParen reports an opening parenthesis without a matching closing one.
+	UnclosedParen
+	// MissingOperand reports an operator lacking an operand as well as an empty expression. It is reported by
+	// evaluators consuming tokens in RPN, which detect missing operands only when applying an operator.
+	MissingOperand
+)
+
+func (k SyntaxErrorKind) String() string {
+	switch k {
+	case UnexpectedToken:
+		return "unexpected token"
+	case UnclosedParen:
+		return "unclosed parenthesis"
+	case MissingOperand:
+		return "missing operand"
+	default:
+		return fmt.Sprintf("SyntaxErrorKind(%d)", int(k))
+	}
+}
+
+// SyntaxError describes a syntax error. It wraps ErrInvalidSyntax.
+type SyntaxError struct {
+	Kind SyntaxErrorKind
+	Span token.Span
+	// Found is the offending token or the empty string if the input ended prematurely.
+	Found string
+	// Expected lists the tokens that would have been valid in place of Found.
+	Expected []string
+	// Opening is the span of the opening parenthesis for UnclosedParen.
+	Opening token.Span
+}
+
+// Message returns the error's message without the position prefix.
+func (e *SyntaxError) Message() string {
+	found := "end of input"
+	if e.Found != "" {
+		found = strconv.Quote(e.Found)
+	}
+
+	switch e.Kind {
+	case UnclosedParen:
+		return fmt.Sprintf("%v: expected ) but got %s (unclosed ( at %s)", ErrInvalidSyntax, found, e.Opening)
+	case MissingOperand:
+		if e.Found == "" {
+			return fmt.Sprintf("%v: missing operand", ErrInvalidSyntax)
+		}
+		return fmt.Sprintf("%v: missing operand for %s", ErrInvalidSyntax, found)
+	default:
+		return fmt.Sprintf("%v: unexpected %s", ErrInvalidSyntax, found)
+	}
+}
+
+func (e *SyntaxError) Error() string { return fmt.Sprintf("%s: %s", e.Span, e.Message()) }
+
+func (e *SyntaxError) Unwrap() error { return ErrInvalidSyntax }
+
+// ErrorList is a list of errors reported in recovery mode by a Parser or an rpn.RPN, ordered by their position.
+type ErrorList []error
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	case 2:
+		return fmt.Sprintf("%s (and 1 more error)", l[0])
+	default:
+		return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+	}
+}
+
+func (l ErrorList) Unwrap() []error { return l }
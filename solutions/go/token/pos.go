@@ -0,0 +1,94 @@
+package token
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Pos describes a position in the input.
+type Pos struct {
+	// Offset is the byte offset, starting at 0.
+	Offset int
+	// Line is the line number, starting at 1.
+	Line int
+	// Column is the number of the rune in its line, starting at 1.
+	Column int
+}
+
+// IsValid returns whether p describes a position in the input, which is not the case for the zero value.
+func (p Pos) IsValid() bool { return p.Line > 0 }
+
+func (p Pos) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Span describes a contiguous range of input. Start is the position of the first rune, End the position
+// immediately following the last rune.
+type Span struct {
+	Start, End Pos
+}
+
+func (s Span) String() string { return s.Start.String() }
+
+// Lines records the line breaks and multi-byte runes of input in order to derive positions from offsets, so
+// that tokens need to carry their offset only. It takes memory proportional to the number of lines and of
+// non-ASCII runes rather than to the length of the input. The zero value is ready to use.
+type Lines struct {
+	// starts contains the offset of every line but the first.
+	starts []int
+	// wide contains the offset and the size of every multi-byte rune.
+	wide []wideRune
+}
+
+type wideRune struct {
+	offset, size int
+}
+
+// AddLine records a line starting at offset. Offsets not following the line recorded last are ignored, so
+// input read again after unreading it is not recorded twice.
+func (l *Lines) AddLine(offset int) {
+	if n := len(l.starts); n == 0 || offset > l.starts[n-1] {
+		l.starts = append(l.starts, offset)
+	}
+}
+
+// AddRune records a rune of size bytes at offset, which counts as a single column. Offsets not following
+// the rune recorded last are ignored.
+func (l *Lines) AddRune(offset, size int) {
+	if n := len(l.wide); n == 0 || offset > l.wide[n-1].offset {
+		l.wide = append(l.wide, wideRune{offset: offset, size: size})
+	}
+}
+
+// Reset discards all lines and runes recorded, keeping the memory allocated.
+func (l *Lines) Reset() {
+	l.starts, l.wide = l.starts[:0], l.wide[:0]
+}
+
+// Pos returns the position of offset. A nil Lines returns a position carrying the offset only.
+func (l *Lines) Pos(offset int) Pos {
+	if l == nil {
+		return Pos{Offset: offset}
+	}
+
+	line := sort.SearchInts(l.starts, offset+1)
+	start := 0
+	if line > 0 {
+		start = l.starts[line-1]
+	}
+
+	column := offset - start + 1
+	for i := sort.Search(len(l.wide), func(i int) bool { return l.wide[i].offset >= start }); i < len(l.wide) && l.wide[i].offset < offset; i++ {
+		column -= l.wide[i].size - 1
+	}
+
+	return Pos{Offset: offset, Line: line + 1, Column: column}
+}
+
+// Span returns the span from start to end.
+func (l *Lines) Span(start, end int) Span {
+	return Span{Start: l.Pos(start), End: l.Pos(end)}
+}
@@ -0,0 +1,145 @@
+// Package token contains types and support functions to represent tokens of
+// the input language.
+package token
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Type identifies the kind of a Token.
+type Type int
+
+const (
+	Number Type = iota + 1
+	// Ident is an identifier, such as the name of a variable.
+	Ident
+	Add
+	Sub
+	Mul
+	Div
+	Pow
+	LParen
+	RParen
+	// Comma separates the arguments of a function call.
+	Comma
+	// Assign assigns the value of an expression to a variable in a script.
+	Assign
+	// Semicolon terminates a statement of a script. In script mode, the scanner emits a Semicolon with the
+	// Literal "\n" for a line break terminating a statement.
+	Semicolon
+
+	// Neg and Plus represent a prefix sign. They are never produced by the
+	// scanner, which emits Sub and Add for any sign. The parser and the RPN
+	// converter rewrite those based on their position in the token stream.
+	Neg
+	Plus
+
+	// Call is a call of the function named Literal with Arity arguments. It is never produced by the scanner,
+	// which emits the function name as an Ident. The RPN converter emits it following the arguments.
+	Call
+)
+
+func (t Type) String() string {
+	switch t {
+	case Number:
+		return "number"
+	case Ident:
+		return "identifier"
+	case Add, Plus:
+		return "+"
+	case Sub, Neg:
+		return "-"
+	case Mul:
+		return "*"
+	case Div:
+		return "/"
+	case Pow:
+		return "^"
+	case LParen:
+		return "("
+	case RParen:
+		return ")"
+	case Comma:
+		return ","
+	case Assign:
+		return "="
+	case Semicolon:
+		return ";"
+	case Call:
+		return "call"
+	default:
+		return fmt.Sprintf("Type(%d)", int(t))
+	}
+}
+
+// Token is a token of the input language along with the offset of input it has been read from. A token only
+// carries its start offset, which keeps it small; positions made of line and column are derived from offsets
+// on demand using a Locator, such as syntax.Scanner.
+type Token struct {
+	Type Type
+	// Value is the value of a Number token.
+	Value float64
+	// Literal is the source text of a Number token, the name of an Ident or Call token and the name of the
+	// variable assigned to for an Assign token yielded by the RPN converter.
+	Literal string
+	// Arity is the number of arguments of a Call token.
+	Arity int
+	// Offset is the byte offset of the token's first rune in the input.
+	Offset int
+}
+
+// End returns the offset immediately following t as far as it can be derived from t itself: tokens with a
+// Literal span its length and any other token a single byte, except for Number tokens without a Literal and
+// the zero Token, which are empty.
+func (t Token) End() int {
+	switch {
+	case t.Literal != "":
+		return t.Offset + len(t.Literal)
+	case t.Type == 0 || t.Type == Number:
+		return t.Offset
+	default:
+		return t.Offset + 1
+	}
+}
+
+func (t Token) String() string {
+	if t.Type == Ident || t.Type == Call {
+		return t.Literal
+	}
+	if t.Type == Number {
+		if t.Literal != "" {
+			return t.Literal
+		}
+		return strconv.FormatFloat(t.Value, 'g', -1, 64)
+	}
+	if t.Type == Semicolon && t.Literal == "\n" {
+		return "newline"
+	}
+	return t.Type.String()
+}
+
+// IsOperator returns whether t is a binary operator.
+func IsOperator(t Token) bool {
+	return t.Type == Add || t.Type == Sub || t.Type == Mul || t.Type == Div || t.Type == Pow
+}
+
+// IsUnary returns whether t is a prefix sign.
+func IsUnary(t Token) bool {
+	return t.Type == Neg || t.Type == Plus
+}
+
+// Reader is implemented by types yielding tokens one at a time, such as syntax.Scanner and rpn.RPN. Next
+// returns io.EOF once no more tokens are available.
+type Reader interface {
+	Next() (Token, error)
+}
+
+// Locator is implemented by Readers deriving positions from the offsets of the tokens they yield, such as
+// syntax.Scanner and rpn.RPN.
+type Locator interface {
+	// Pos returns the position of offset, which must not exceed the input read so far.
+	Pos(offset int) Pos
+	// Offset returns the offset immediately following the last token read from the input.
+	Offset() int
+}